@@ -0,0 +1,177 @@
+// Copyright 2022 The X Programming Language.
+// Use of this source code is governed by a BSD 3-Clause
+// license that can be found in the LICENSE file.
+
+#ifndef __XXC_TEST_HPP
+#define __XXC_TEST_HPP
+
+#include <chrono>
+#include <iomanip>
+#ifndef _WINDOWS
+#include <cerrno>
+#include <unistd.h>
+#include <sys/wait.h>
+#endif // #ifndef _WINDOWS
+
+// Test function of package.
+struct x_test;
+
+// Declarations
+
+// Runs test and reports result, returns true if test is passed.
+// Tests are runs in own process if platform supports,
+// so panics which are terminates program fails only their test.
+bool x_run_test(const x_test &_Test) noexcept;
+// Runs tests and reports summary, returns exit code.
+int x_run_tests(const std::vector<x_test> &_Tests) noexcept;
+// Test list of package, generates by XXC.
+std::vector<x_test> _xxc___tests(void);
+
+// Definitions
+
+struct x_test {
+    const char *_name;
+    void(*_function)(void);
+};
+
+#ifndef _WINDOWS
+// Pipe to write panic message of test process.
+int x_test_panic_fd{-1};
+#else
+// Test which is running, for report it if program terminates.
+const x_test *x_running_test{nil};
+#endif // #ifndef _WINDOWS
+
+// Returns panic message of current exception.
+std::string x_test_panic(void) noexcept {
+    std::ostringstream _message;
+    try { throw; }
+    catch (trait<XID(Error)> _error) { _message << _error.get().error(); }
+    catch (const std::exception &_error) { _message << _error.what(); }
+    catch (...) { _message << "unknown panic"; }
+    return _message.str();
+}
+
+// Calls test function, returns true if test is passed.
+bool x_call_test(const x_test &_Test, std::string &_Panic) noexcept {
+    try {
+        _Test._function();
+        return true;
+    } catch (...) {
+        _Panic = x_test_panic();
+    }
+    return false;
+}
+
+void x_report_test(const x_test &_Test,
+                   const bool _Passed,
+                   const std::string &_Panic,
+                   const std::chrono::steady_clock::time_point _Start) noexcept {
+    const std::chrono::duration<double> _elapsed{
+        std::chrono::steady_clock::now() - _Start};
+    std::cout << (_Passed ? "--- PASS: " : "--- FAIL: ")
+              << _Test._name
+              << " (" << std::fixed << std::setprecision(3)
+              << _elapsed.count() << "s)" << std::endl;
+    if (!_Passed) { std::cout << "    panic: " << _Panic << std::endl; }
+}
+
+#ifndef _WINDOWS
+// Terminate handler of test process, panics which are reaches to
+// noexcept functions calls terminate instead of unwind to test.
+void x_test_terminate_handler(void) noexcept {
+    const std::string _panic{
+        std::current_exception() ? x_test_panic() : "terminated"};
+    std::cout.flush();
+    if (write(x_test_panic_fd, _panic.c_str(), _panic.length()) < 0) {}
+    std::_Exit(X_EXIT_PANIC);
+}
+
+bool x_run_test(const x_test &_Test) noexcept {
+    const auto _start{std::chrono::steady_clock::now()};
+    int _pipe[2];
+    std::cout.flush();
+    if (pipe(_pipe) == -1) {
+        x_report_test(_Test, false, std::strerror(errno), _start);
+        return false;
+    }
+    const pid_t _pid{fork()};
+    if (_pid == 0) {
+        close(_pipe[0]);
+        x_test_panic_fd = _pipe[1];
+        std::set_terminate(&x_test_terminate_handler);
+        std::string _panic;
+        const bool _passed{x_call_test(_Test, _panic)};
+        std::cout.flush();
+        if (!_passed &&
+            write(x_test_panic_fd, _panic.c_str(), _panic.length()) < 0) {}
+        std::_Exit(_passed ? EXIT_SUCCESS : X_EXIT_PANIC);
+    }
+    close(_pipe[1]);
+    std::string _panic;
+    if (_pid == -1) {
+        _panic = std::strerror(errno);
+    } else {
+        char _buffer[256];
+        ssize_t _n;
+        while ((_n = read(_pipe[0], _buffer, sizeof(_buffer))) > 0)
+        { _panic.append(_buffer, _n); }
+    }
+    close(_pipe[0]);
+    int _status{0};
+    bool _passed{false};
+    if (_pid != -1 && waitpid(_pid, &_status, 0) != -1) {
+        _passed = WIFEXITED(_status) && WEXITSTATUS(_status) == EXIT_SUCCESS;
+        // Test process is crashed or exited without panic.
+        if (!_passed && _panic.empty()) {
+            if (WIFSIGNALED(_status)) {
+                _panic = "signal: " + std::string(strsignal(WTERMSIG(_status)));
+            } else {
+                _panic = "exit status " + std::to_string(WEXITSTATUS(_status));
+            }
+        }
+    }
+    x_report_test(_Test, _passed, _panic, _start);
+    return _passed;
+}
+#else
+// Terminate handler of tests, reports running test as failed.
+// Remaining tests are not runs, program is terminated.
+void x_test_terminate_handler(void) noexcept {
+    const std::string _panic{
+        std::current_exception() ? x_test_panic() : "terminated"};
+    std::cout << "--- FAIL: " << x_running_test->_name << std::endl
+              << "    panic: " << _panic << std::endl
+              << "FAIL" << std::endl
+              << "remaining tests are not run" << std::endl;
+    std::exit(EXIT_FAILURE);
+}
+
+bool x_run_test(const x_test &_Test) noexcept {
+    const auto _start{std::chrono::steady_clock::now()};
+    x_running_test = &_Test;
+    std::set_terminate(&x_test_terminate_handler);
+    std::string _panic;
+    const bool _passed{x_call_test(_Test, _panic)};
+    x_report_test(_Test, _passed, _panic, _start);
+    return _passed;
+}
+#endif // #ifndef _WINDOWS
+
+int x_run_tests(const std::vector<x_test> &_Tests) noexcept {
+    uint_xt _failed{0};
+    const auto _start{std::chrono::steady_clock::now()};
+    for (const x_test &_test: _Tests) {
+        if (!x_run_test(_test)) { ++_failed; }
+    }
+    const std::chrono::duration<double> _elapsed{
+        std::chrono::steady_clock::now() - _start};
+    std::cout << (_failed == 0 ? "PASS" : "FAIL") << std::endl
+              << _Tests.size()-_failed << " passed, "
+              << _failed << " failed ("
+              << std::fixed << std::setprecision(3)
+              << _elapsed.count() << "s)" << std::endl;
+    return _failed == 0 ? EXIT_SUCCESS : EXIT_FAILURE;
+}
+
+#endif // #ifndef __XXC_TEST_HPP
@@ -49,6 +49,9 @@ void XID(panic)(const char *_Message);
 #include "ptr.hpp"
 #include "defer.hpp"
 #include "builtin.hpp"
+#ifdef _XXC_TEST
+#include "test.hpp"
+#endif // #ifdef _XXC_TEST
 
 // Declarations
 
@@ -186,7 +189,7 @@ void x_terminate_handler(void) noexcept {
 
 inline void XID(panic)(const char *_Message) {
     struct panic_error: public XID(Error) {
-        const char *_message;
+        str_xt _message;
         str_xt error(void) { return this->_message; }
     };
     panic_error _error;
@@ -204,9 +207,13 @@ int main(void) {
 #endif
 
     _xxc___call_initializers();
+#ifdef _XXC_TEST
+    return x_run_tests(_xxc___tests());
+#else
     XID(main());
 
     return EXIT_SUCCESS;
+#endif // #ifdef _XXC_TEST
 }
 
 #endif // #ifndef __XXC_HPP
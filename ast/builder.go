@@ -2,6 +2,7 @@ package ast
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 
@@ -42,8 +43,11 @@ func compilerErr(tok Tok, key string, args ...any) xlog.CompilerLog {
 		Type:    xlog.Error,
 		Row:     tok.Row,
 		Column:  tok.Column,
+		Length:  tok.Length(),
 		Path:    tok.File.Path(),
 		Message: x.GetError(key, args...),
+		Key:     key,
+		Args:    args,
 	}
 }
 
@@ -259,6 +263,7 @@ func (b *Builder) Comment(tok Tok) models.Object {
 	return models.Object{
 		Tok: tok,
 		Data: models.Comment{
+			Tok:     tok,
 			Content: tok.Kind,
 		},
 	}
@@ -271,10 +276,11 @@ func (b *Builder) Preprocessor(toks Toks) {
 		return
 	}
 	var pp models.Preprocessor
+	pp.Tok = toks[0]
 	toks = toks[1:] // Remove directive mark
 	tok := toks[0]
 	if tok.Id != tokens.Id {
-		b.pusherr(pp.Tok, "invalid_syntax")
+		b.pusherr(tok, "invalid_syntax")
 		return
 	}
 	ok := false
@@ -311,6 +317,8 @@ func (b *Builder) PreprocessorDirective(pp *models.Preprocessor, toks Toks) bool
 	switch tok.Kind {
 	case x.PreprocessorDirectiveEnofi:
 		ok = b.directiveEnofi(&d, toks)
+	case x.PreprocessorDirectiveAllow:
+		ok = b.directiveAllow(&d, toks)
 	default:
 		b.pusherr(tok, "invalid_pragma_directive")
 	}
@@ -327,6 +335,27 @@ func (b *Builder) directiveEnofi(d *models.Directive, toks Toks) bool {
 	return true
 }
 
+func (b *Builder) directiveAllow(d *models.Directive, toks Toks) bool {
+	if len(toks) == 1 {
+		b.pusherr(toks[0], "missing_warning_key")
+		return false
+	}
+	var allow models.DirectiveAllow
+	for _, tok := range toks[1:] {
+		if tok.Id != tokens.Id {
+			b.pusherr(tok, "invalid_syntax")
+			return false
+		}
+		if _, ok := x.Warnings[tok.Kind]; !ok {
+			b.pusherr(tok, "undefined_warning_key", tok.Kind)
+			return false
+		}
+		allow.Keys = append(allow.Keys, tok.Kind)
+	}
+	d.Command = allow
+	return true
+}
+
 // Id builds AST model of global id statement.
 func (b *Builder) Id(toks Toks) {
 	if len(toks) == 1 {
@@ -675,25 +704,72 @@ func (b *Builder) buildUseCppDecl(use *models.Use, toks Toks) {
 	use.Path = tok.Kind[1 : len(tok.Kind)-1]
 }
 
+// useAlias sets alias of use declaration if exist
+// and removes alias tokens from toks.
+func (b *Builder) useAlias(use *models.Use, toks *Toks) {
+	i := len(*toks) - 2
+	if i < 0 {
+		return
+	}
+	tok := (*toks)[i]
+	if tok.Id != tokens.Id || tok.Kind != tokens.AS {
+		return
+	}
+	aliasTok := (*toks)[i+1]
+	*toks = (*toks)[:i]
+	if aliasTok.Id != tokens.Id {
+		b.pusherr(aliasTok, "invalid_syntax")
+		return
+	} else if xapi.IsIgnoreId(aliasTok.Kind) {
+		b.pusherr(aliasTok, "ignore_id")
+		return
+	}
+	if len(*toks) == 0 {
+		b.pusherr(tok, "invalid_syntax")
+		return
+	}
+	use.Alias = aliasTok.Kind
+}
+
+// projectPath returns path of project for project-local packages.
+// Directory of file is the project if settings are not loaded,
+// such as dumping syntax tree of a file without X settings.
+func projectPath(tok Tok) string {
+	if x.ProjectPath != "" || tok.File == nil {
+		return x.ProjectPath
+	}
+	dir, _ := filepath.Abs(tok.File.Dir)
+	return dir
+}
+
 func (b *Builder) buildUseDecl(use *models.Use, toks Toks) {
 	var path strings.Builder
-	path.WriteString(x.StdlibPath)
-	path.WriteRune(os.PathSeparator)
 	tok := toks[0]
 	isStd := false
 	if tok.Id == tokens.Cpp {
 		b.buildUseCppDecl(use, toks)
 		return
 	}
-	if tok.Id != tokens.Id || tok.Kind != "std" {
+	if tok.Id != tokens.Id {
 		b.pusherr(toks[0], "invalid_syntax")
+		return
 	}
-	isStd = true
-	if len(toks) < 3 {
-		b.pusherr(tok, "invalid_syntax")
+	b.useAlias(use, &toks)
+	if len(toks) == 0 {
 		return
 	}
-	toks = toks[2:]
+	if tok.Kind == x.Stdlib {
+		isStd = true
+		if len(toks) < 3 {
+			b.pusherr(tok, "invalid_syntax")
+			return
+		}
+		toks = toks[2:]
+		path.WriteString(x.StdlibPath)
+	} else { // Project-local package.
+		path.WriteString(projectPath(tok))
+	}
+	path.WriteRune(os.PathSeparator)
 	tok = toks[len(toks)-1]
 	switch tok.Id {
 	case tokens.DoubleColon:
@@ -758,7 +834,7 @@ func (b *Builder) buildUseDecl(use *models.Use, toks Toks) {
 	}
 	use.LinkString = tokstoa(toks)
 	if isStd {
-		use.LinkString = "std::" + use.LinkString
+		use.LinkString = x.Stdlib + tokens.DOUBLE_COLON + use.LinkString
 	}
 	use.Path = path.String()
 }
@@ -768,6 +844,10 @@ func (b *Builder) Attribute(toks Toks) (a models.Attribute) {
 	i := 0
 	a.Tok = toks[i]
 	i++
+	if i >= len(toks) {
+		b.pusherr(a.Tok, "invalid_syntax")
+		return
+	}
 	a.Tag = toks[i]
 	if a.Tag.Id != tokens.Id || a.Tok.Column+1 != a.Tag.Column {
 		b.pusherr(a.Tag, "invalid_syntax")
@@ -842,6 +922,7 @@ func (b *Builder) Func(toks Toks, anon, prototype bool) (f models.Func) {
 		b.pusherr(toks[i], "invalid_syntax")
 	}
 	f.Block = b.Block(blockToks)
+	f.Block.End = toks[i-1]
 	return
 }
 
@@ -1073,6 +1154,10 @@ func (b *Builder) idDataTypePartEnd(t *models.DataType, dtv *strings.Builder, to
 	dtv.WriteByte('[')
 	var genericsStr strings.Builder
 	parts := b.idGenericsParts(toks, i)
+	if len(parts) == 0 {
+		b.pusherr(tok, "missing_generics")
+		return
+	}
 	generics := make([]models.DataType, len(parts))
 	for i, part := range parts {
 		index := 0
@@ -1321,6 +1406,7 @@ func (b *Builder) funcMultiTypeRet(toks Toks, i *int) (t models.RetType, ok bool
 		t.Identifiers = append(t.Identifiers, param.Tok)
 	}
 	if len(types) > 1 {
+		t.Type.Tok = toks[start]
 		t.Type.MultiTyped = true
 		t.Type.Tag = types
 	} else {
@@ -1828,6 +1914,7 @@ func (b *Builder) CommentStatement(tok Tok) (s models.Statement) {
 	s.Tok = tok
 	tok.Kind = strings.TrimSpace(tok.Kind[2:])
 	s.Data = models.Comment{
+		Tok:     tok,
 		Content: tok.Kind,
 	}
 	return
@@ -2181,9 +2268,11 @@ func (b *Builder) cases(toks Toks) ([]models.Case, *models.Case) {
 				*def = c
 				break
 			}
-			fallthrough
+			b.pusherr(tok, "invalid_syntax")
 		default:
 			b.pusherr(tok, "invalid_syntax")
+			// Tokens are not consumed, remaining is not cases.
+			return cases, def
 		}
 	}
 	return cases, def
@@ -0,0 +1,45 @@
+package ast
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/the-xlang/xxc/ast/models"
+	"github.com/the-xlang/xxc/lex"
+	"github.com/the-xlang/xxc/pkg/x"
+	"github.com/the-xlang/xxc/pkg/xio"
+)
+
+func TestUsePath(t *testing.T) {
+	dir := t.TempDir()
+	project := filepath.Join(dir, "project")
+	cases := []struct {
+		name        string
+		projectPath string
+		want        string
+	}{
+		{"project", project, filepath.Join(project, "lib", "math")},
+		// Settings are not loaded.
+		{"directory of file", "", filepath.Join(dir, "lib", "math")},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer func(path string) { x.ProjectPath = path }(x.ProjectPath)
+			x.ProjectPath = c.projectPath
+			f := &xio.File{Dir: dir, Name: "main.xx", Data: []rune("use lib::math\n")}
+			l := lex.NewLex(f)
+			b := NewBuilder(l.Lex())
+			b.Build()
+			if len(b.Errors) > 0 {
+				t.Fatalf("unexpected errors: %+v", b.Errors)
+			}
+			use, ok := b.Tree[0].Data.(models.Use)
+			if !ok {
+				t.Fatalf("use declaration is not built: %T", b.Tree[0].Data)
+			}
+			if use.Path != c.want {
+				t.Errorf("path is %s, want %s", use.Path, c.want)
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@ type Block struct {
 	Gotos    *Gotos
 	Labels   *Labels
 	Func     *Func
+	End      Tok // Closing brace, only for function blocks.
 }
 
 func (b Block) String() string {
@@ -33,6 +34,7 @@ func ParseBlock(b Block) string {
 			continue
 		}
 		cpp.WriteByte('\n')
+		cpp.WriteString(LineDirective(s.Tok))
 		cpp.WriteString(IndentString())
 		cpp.WriteString(s.String())
 	}
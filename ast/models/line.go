@@ -0,0 +1,62 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/the-xlang/xxc/pkg/x"
+)
+
+// LineDirective returns #line directive of token with new line,
+// empty string if line directives are disabled or token has not file.
+func LineDirective(tok Tok) string {
+	if !x.Set.LineDirectives || tok.File == nil {
+		return ""
+	}
+	return lineDirective(tok.Row, tok.File.Path())
+}
+
+func lineDirective(row int, path string) string {
+	path = strings.ReplaceAll(path, `\`, `\\`)
+	path = strings.ReplaceAll(path, `"`, `\"`)
+	var cpp strings.Builder
+	cpp.WriteString("#line ")
+	cpp.WriteString(strconv.Itoa(row))
+	cpp.WriteString(` "`)
+	cpp.WriteString(path)
+	cpp.WriteString("\"\n")
+	return cpp.String()
+}
+
+// Mark of generated code which is not belongs to X code.
+// It is invalid directive, so it fails compilation if not resolved.
+const genLineMark = "#line __xxc_generated__"
+
+// GenLineDirective returns mark of generated code with new line, empty
+// string if line directives are disabled. It is written after X code
+// for map following generated code back to generated cpp file,
+// marks are replaced with #line directives by ResolveLineDirectives.
+func GenLineDirective() string {
+	if !x.Set.LineDirectives {
+		return ""
+	}
+	return genLineMark + "\n"
+}
+
+// ResolveLineDirectives returns cpp code which is marks of generated code
+// are replaced with #line directives of lines of cpp file at path.
+func ResolveLineDirectives(cpp, path string) string {
+	if !strings.Contains(cpp, genLineMark) {
+		return cpp
+	}
+	lines := strings.SplitAfter(cpp, "\n")
+	var resolved strings.Builder
+	for i, line := range lines {
+		if strings.TrimSuffix(line, "\n") == genLineMark {
+			// Line after directive is the next line of file.
+			line = lineDirective(i+2, path)
+		}
+		resolved.WriteString(line)
+	}
+	return resolved.String()
+}
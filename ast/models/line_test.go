@@ -0,0 +1,40 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/the-xlang/xxc/pkg/x"
+	"github.com/the-xlang/xxc/pkg/xset"
+)
+
+func TestResolveLineDirectives(t *testing.T) {
+	defer func(set *xset.XSet) { x.Set = set }(x.Set)
+	set := *xset.Default
+	x.Set = &set
+	x.Set.LineDirectives = false
+	if d := GenLineDirective(); d != "" {
+		t.Fatalf("disabled line directives: got mark %q", d)
+	}
+	x.Set.LineDirectives = true
+	cpp := "#include \"xxc.hpp\"\n" +
+		"#line 3 \"main.xx\"\n" +
+		"void f(void) {}\n" +
+		GenLineDirective() +
+		"\n" +
+		"void _xxc___call_initializers(void) {}\n" +
+		GenLineDirective()
+	want := "#include \"xxc.hpp\"\n" +
+		"#line 3 \"main.xx\"\n" +
+		"void f(void) {}\n" +
+		"#line 5 \"/out/x \\\"1\\\".cpp\"\n" +
+		"\n" +
+		"void _xxc___call_initializers(void) {}\n" +
+		"#line 8 \"/out/x \\\"1\\\".cpp\"\n"
+	if got := ResolveLineDirectives(cpp, `/out/x "1".cpp`); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	plain := "int main(void) {}\n"
+	if got := ResolveLineDirectives(plain, "x.cpp"); got != plain {
+		t.Errorf("code without marks is changed: %q", got)
+	}
+}
@@ -0,0 +1,80 @@
+package models
+
+// Node is the AST model which is have position.
+// Pos returns token of position of node.
+// Token is zero if node has not position such as empty blocks.
+type Node interface {
+	Pos() Tok
+}
+
+func (o Object) Pos() Tok          { return o.Tok }
+func (s Statement) Pos() Tok       { return s.Tok }
+func (c Comment) Pos() Tok         { return c.Tok }
+func (a Attribute) Pos() Tok       { return a.Tok }
+func (gt GenericType) Pos() Tok    { return gt.Tok }
+func (pp Preprocessor) Pos() Tok   { return pp.Tok }
+func (u Use) Pos() Tok             { return u.Tok }
+func (cl CppLink) Pos() Tok        { return cl.Tok }
+func (ns Namespace) Pos() Tok      { return ns.Tok }
+func (dt DataType) Pos() Tok       { return dt.Tok }
+func (f Func) Pos() Tok            { return f.Tok }
+func (p Param) Pos() Tok           { return p.Tok }
+func (rt RetType) Pos() Tok        { return rt.Type.Tok }
+func (v Var) Pos() Tok             { return v.Token }
+func (t Type) Pos() Tok            { return t.Tok }
+func (e Enum) Pos() Tok            { return e.Tok }
+func (ei EnumItem) Pos() Tok       { return ei.Tok }
+func (s Struct) Pos() Tok          { return s.Tok }
+func (t Trait) Pos() Tok           { return t.Tok }
+func (a Assign) Pos() Tok          { return a.Setter }
+func (r Ret) Pos() Tok             { return r.Tok }
+func (d Defer) Pos() Tok           { return d.Tok }
+func (cc ConcurrentCall) Pos() Tok { return cc.Tok }
+func (i Iter) Pos() Tok            { return i.Tok }
+func (f IterForeach) Pos() Tok     { return f.InTok }
+func (i If) Pos() Tok              { return i.Tok }
+func (ei ElseIf) Pos() Tok         { return ei.Tok }
+func (e Else) Pos() Tok            { return e.Tok }
+func (m Match) Pos() Tok           { return m.Tok }
+func (c Case) Pos() Tok            { return c.Tok }
+func (f Fallthrough) Pos() Tok     { return f.Tok }
+func (b Break) Pos() Tok           { return b.Tok }
+func (c Continue) Pos() Tok        { return c.Tok }
+func (g Goto) Pos() Tok            { return g.Tok }
+func (l Label) Pos() Tok           { return l.Tok }
+func (es ExprStatement) Pos() Tok  { return es.Expr.Pos() }
+func (w IterWhile) Pos() Tok       { return w.Expr.Pos() }
+func (f IterFor) Pos() Tok         { return f.Condition.Pos() }
+
+// Pos returns the first token of expression.
+func (e Expr) Pos() Tok {
+	if len(e.Toks) == 0 {
+		return Tok{}
+	}
+	return e.Toks[0]
+}
+
+// Pos returns position of new variable or expression.
+func (as AssignLeft) Pos() Tok {
+	if as.Var.New {
+		return as.Var.Token
+	}
+	return as.Expr.Pos()
+}
+
+// Pos returns trait of implementation or target if there is no trait.
+func (i Impl) Pos() Tok {
+	if i.Trait.Kind != "" {
+		return i.Trait
+	}
+	return i.Target.Tok
+}
+
+// Pos returns the first statement of block,
+// or closing brace if block is empty.
+func (b Block) Pos() Tok {
+	if len(b.Tree) > 0 {
+		return b.Tree[0].Tok
+	}
+	return b.End
+}
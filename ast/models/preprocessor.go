@@ -23,3 +23,8 @@ func (d Directive) String() string {
 
 // DirectiveEnofi is the AST model of enofi directive.
 type DirectiveEnofi struct{}
+
+// DirectiveAllow is the AST model of allow directive.
+type DirectiveAllow struct {
+	Keys []string
+}
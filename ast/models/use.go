@@ -8,4 +8,5 @@ type Use struct {
 	LinkString string
 	FullUse    bool
 	Selectors  []Tok
+	Alias      string
 }
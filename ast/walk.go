@@ -0,0 +1,344 @@
+package ast
+
+import "github.com/the-xlang/xxc/ast/models"
+
+// Visitor visits nodes of AST by Walk.
+// Visit is called for each node, children of node are visited
+// with returned visitor if it is not nil. Visit is called with
+// nil node after children of node.
+type Visitor interface {
+	Visit(node models.Node) (w Visitor)
+}
+
+// Walk traverses AST in depth-first order.
+// Absent nodes such as empty expressions and implicit data types
+// are not visited.
+// Data of objects and statements which is not node is not visited.
+func Walk(v Visitor, node models.Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+	switch n := node.(type) {
+	case models.Object:
+		walkData(v, n.Data)
+	case *models.Object:
+		walkData(v, n.Data)
+	case models.Statement:
+		walkData(v, n.Data)
+	case *models.Statement:
+		walkData(v, n.Data)
+	case models.Namespace:
+		walkObjects(v, n.Tree)
+	case *models.Namespace:
+		walkObjects(v, n.Tree)
+	case models.Impl:
+		walkImpl(v, &n)
+	case *models.Impl:
+		walkImpl(v, n)
+	case models.CppLink:
+		walkFunc(v, n.Link)
+	case *models.CppLink:
+		walkFunc(v, n.Link)
+	case models.DataType:
+		walkTypes(v, &n)
+	case *models.DataType:
+		walkTypes(v, n)
+	case models.Func:
+		walkFunc(v, &n)
+	case *models.Func:
+		walkFunc(v, n)
+	case models.Param:
+		walkParam(v, &n)
+	case *models.Param:
+		walkParam(v, n)
+	case models.RetType:
+		walkType(v, n.Type)
+	case *models.RetType:
+		walkType(v, n.Type)
+	case models.Var:
+		walkVar(v, &n)
+	case *models.Var:
+		walkVar(v, n)
+	case models.Type:
+		walkType(v, n.Type)
+	case *models.Type:
+		walkType(v, n.Type)
+	case models.Enum:
+		walkEnum(v, &n)
+	case *models.Enum:
+		walkEnum(v, n)
+	case models.EnumItem:
+		walkExpr(v, n.Expr)
+	case *models.EnumItem:
+		walkExpr(v, n.Expr)
+	case models.Struct:
+		walkStruct(v, &n)
+	case *models.Struct:
+		walkStruct(v, n)
+	case models.Trait:
+		walkTrait(v, &n)
+	case *models.Trait:
+		walkTrait(v, n)
+	case models.Block:
+		walkStatements(v, n.Tree)
+	case *models.Block:
+		walkStatements(v, n.Tree)
+	case models.ExprStatement:
+		walkExpr(v, n.Expr)
+	case *models.ExprStatement:
+		walkExpr(v, n.Expr)
+	case models.Assign:
+		walkAssign(v, &n)
+	case *models.Assign:
+		walkAssign(v, n)
+	case models.AssignLeft:
+		walkAssignLeft(v, &n)
+	case *models.AssignLeft:
+		walkAssignLeft(v, n)
+	case models.Ret:
+		walkExpr(v, n.Expr)
+	case *models.Ret:
+		walkExpr(v, n.Expr)
+	case models.Defer:
+		walkExpr(v, n.Expr)
+	case *models.Defer:
+		walkExpr(v, n.Expr)
+	case models.ConcurrentCall:
+		walkExpr(v, n.Expr)
+	case *models.ConcurrentCall:
+		walkExpr(v, n.Expr)
+	case models.Iter:
+		walkIter(v, &n)
+	case *models.Iter:
+		walkIter(v, n)
+	case models.IterFor:
+		walkIterFor(v, &n)
+	case *models.IterFor:
+		walkIterFor(v, n)
+	case models.IterWhile:
+		walkExpr(v, n.Expr)
+	case *models.IterWhile:
+		walkExpr(v, n.Expr)
+	case models.IterForeach:
+		walkIterForeach(v, &n)
+	case *models.IterForeach:
+		walkIterForeach(v, n)
+	case models.If:
+		walkExpr(v, n.Expr)
+		walkBlock(v, n.Block)
+	case *models.If:
+		walkExpr(v, n.Expr)
+		walkBlock(v, n.Block)
+	case models.ElseIf:
+		walkExpr(v, n.Expr)
+		walkBlock(v, n.Block)
+	case *models.ElseIf:
+		walkExpr(v, n.Expr)
+		walkBlock(v, n.Block)
+	case models.Else:
+		walkBlock(v, n.Block)
+	case *models.Else:
+		walkBlock(v, n.Block)
+	case models.Match:
+		walkMatch(v, &n)
+	case *models.Match:
+		walkMatch(v, n)
+	case models.Case:
+		walkCase(v, &n)
+	case *models.Case:
+		walkCase(v, n)
+	}
+	// Others are leaves such as comments, attributes,
+	// labels, expressions and data types.
+	v.Visit(nil)
+}
+
+type inspector func(models.Node) bool
+
+func (f inspector) Visit(node models.Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses AST in depth-first order like Walk.
+// Calls f for each node, children of node are inspected if f returns true.
+// f is called with nil node after children of node.
+func Inspect(node models.Node, f func(models.Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// walkData walks data of object or statement.
+func walkData(v Visitor, data any) {
+	switch t := data.(type) {
+	case []models.GenericType:
+		for _, gt := range t {
+			Walk(v, gt)
+		}
+	case models.Node:
+		Walk(v, t)
+	}
+}
+
+func walkObjects(v Visitor, tree []models.Object) {
+	for _, obj := range tree {
+		Walk(v, obj)
+	}
+}
+
+func walkExpr(v Visitor, e models.Expr) {
+	if len(e.Toks) > 0 {
+		Walk(v, e)
+	}
+}
+
+// walkType walks data type if it is written in source.
+func walkType(v Visitor, t models.DataType) {
+	if t.Tok.File != nil {
+		Walk(v, t)
+	}
+}
+
+func walkStatements(v Visitor, tree []models.Statement) {
+	for _, s := range tree {
+		Walk(v, s)
+	}
+}
+
+// walkTypes walks types of multi-typed data type.
+func walkTypes(v Visitor, t *models.DataType) {
+	if !t.MultiTyped {
+		return
+	}
+	types, _ := t.Tag.([]models.DataType)
+	for _, t := range types {
+		walkType(v, t)
+	}
+}
+
+func walkBlock(v Visitor, b *models.Block) {
+	if b != nil {
+		Walk(v, b)
+	}
+}
+
+func walkFunc(v Visitor, f *models.Func) {
+	if f == nil {
+		return
+	}
+	for _, gt := range f.Generics {
+		Walk(v, gt)
+	}
+	for _, attribute := range f.Attributes {
+		Walk(v, attribute)
+	}
+	if f.Receiver != nil {
+		walkType(v, *f.Receiver)
+	}
+	for i := range f.Params {
+		Walk(v, &f.Params[i])
+	}
+	if f.RetType.Type.Tok.File != nil {
+		Walk(v, &f.RetType)
+	}
+	walkBlock(v, f.Block)
+}
+
+func walkParam(v Visitor, p *models.Param) {
+	walkType(v, p.Type)
+	walkExpr(v, p.Default)
+}
+
+func walkVar(v Visitor, vast *models.Var) {
+	walkType(v, vast.Type)
+	walkExpr(v, vast.Expr)
+}
+
+func walkEnum(v Visitor, e *models.Enum) {
+	walkType(v, e.Type)
+	for _, item := range e.Items {
+		Walk(v, item)
+	}
+}
+
+func walkStruct(v Visitor, s *models.Struct) {
+	for _, gt := range s.Generics {
+		Walk(v, gt)
+	}
+	for _, field := range s.Fields {
+		Walk(v, field)
+	}
+}
+
+func walkTrait(v Visitor, t *models.Trait) {
+	for _, f := range t.Funcs {
+		Walk(v, f)
+	}
+}
+
+func walkImpl(v Visitor, impl *models.Impl) {
+	walkType(v, impl.Target)
+	walkObjects(v, impl.Tree)
+}
+
+func walkAssign(v Visitor, a *models.Assign) {
+	for i := range a.Left {
+		Walk(v, &a.Left[i])
+	}
+	for _, e := range a.Right {
+		walkExpr(v, e)
+	}
+}
+
+func walkAssignLeft(v Visitor, as *models.AssignLeft) {
+	if as.Var.New {
+		Walk(v, &as.Var)
+		return
+	}
+	walkExpr(v, as.Expr)
+}
+
+func walkIter(v Visitor, iter *models.Iter) {
+	if iter.Profile != nil {
+		walkData(v, iter.Profile)
+	}
+	walkBlock(v, iter.Block)
+}
+
+func walkIterFor(v Visitor, f *models.IterFor) {
+	if f.Once.Data != nil {
+		Walk(v, f.Once)
+	}
+	walkExpr(v, f.Condition)
+	if f.Next.Data != nil {
+		Walk(v, f.Next)
+	}
+}
+
+func walkIterForeach(v Visitor, f *models.IterForeach) {
+	if f.KeyA.Id != "" {
+		Walk(v, &f.KeyA)
+	}
+	if f.KeyB.Id != "" {
+		Walk(v, &f.KeyB)
+	}
+	walkExpr(v, f.Expr)
+}
+
+func walkMatch(v Visitor, m *models.Match) {
+	walkExpr(v, m.Expr)
+	for i := range m.Cases {
+		Walk(v, &m.Cases[i])
+	}
+	if m.Default != nil {
+		Walk(v, m.Default)
+	}
+}
+
+func walkCase(v Visitor, c *models.Case) {
+	for _, e := range c.Exprs {
+		walkExpr(v, e)
+	}
+	walkBlock(v, c.Block)
+}
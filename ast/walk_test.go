@@ -0,0 +1,176 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/the-xlang/xxc/ast/models"
+	"github.com/the-xlang/xxc/lex"
+	"github.com/the-xlang/xxc/pkg/xio"
+)
+
+// Source which has every node that builder builds.
+const walkSource = `#pragma enofi
+
+use std::math
+
+cpp sqrt(x f64) f64
+
+//doc: point of plane
+type[T]
+struct point {
+	x: T
+	y: T
+}
+
+trait shape {
+	area() int
+}
+
+impl shape for point {
+	&area() int { ret .x * .y }
+}
+
+enum color: u8 {
+	red = 1,
+	green,
+}
+
+type num int
+
+total: int = 0
+
+@inline
+type[T]
+pair(a T, b T) [T, T] {
+	ret a, b
+}
+
+main() {
+	mut_a, b: = pair[int](1, 2)
+	mut_a, b = b, mut_a
+	total += mut_a
+	if b > 1 {
+		outln(b)
+	} else if b < 0 {
+		outln(0)
+	} else {
+		outln(1)
+	}
+	for b < 10 {
+		b++
+		if b == 5 {
+			continue
+		}
+		if b == 8 {
+			break
+		}
+	}
+	for i: = 0, i < 3, i++ {
+		outln(i)
+	}
+	for i, c in "ab" {
+		outln(i)
+		outln(c)
+	}
+	match b {
+	case 1, 2:
+		outln(b)
+		fallthrough
+	default:
+		outln(0)
+	}
+	defer outln(total)
+	co outln(1)
+again:
+	if b > 20 {
+		goto again
+	}
+	ret
+}
+`
+
+func buildTree(t *testing.T, src string) []models.Object {
+	t.Helper()
+	l := lex.NewLex(&xio.File{Dir: "/x", Name: "main.xx", Data: []rune(src)})
+	toks := l.Lex()
+	if len(l.Logs) > 0 {
+		t.Fatalf("lexer errors: %v", l.Logs)
+	}
+	b := NewBuilder(toks)
+	b.Build()
+	if len(b.Errors) > 0 {
+		t.Fatalf("builder errors: %s", b.Errors[0].Message)
+	}
+	return b.Tree
+}
+
+// nodeName returns type name of node without pointer.
+func nodeName(node models.Node) string {
+	typ := reflect.TypeOf(node)
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ.Name()
+}
+
+func TestWalk(t *testing.T) {
+	visited := map[string]int{}
+	depth := 0
+	for _, obj := range buildTree(t, walkSource) {
+		Inspect(obj, func(node models.Node) bool {
+			if node == nil {
+				depth--
+				return false
+			}
+			depth++
+			visited[nodeName(node)]++
+			if tok := node.Pos(); tok.File == nil || tok.Row < 1 || tok.Column < 1 {
+				t.Errorf("%s has not position: %+v", nodeName(node), tok)
+			}
+			return true
+		})
+	}
+	if depth != 0 {
+		t.Errorf("visits of nil nodes are not balanced: %d", depth)
+	}
+	// Namespaces are not built from source, so not visited.
+	nodes := []string{
+		"Object", "Statement", "Comment", "Attribute", "GenericType",
+		"Preprocessor", "Use", "CppLink", "DataType", "Func", "Param",
+		"RetType", "Var", "Type", "Enum", "EnumItem", "Struct", "Trait",
+		"Impl", "Block", "Assign", "AssignLeft", "Ret", "Defer",
+		"ConcurrentCall", "Iter", "IterWhile", "IterFor", "IterForeach",
+		"If", "ElseIf", "Else", "Match", "Case", "Fallthrough", "Break",
+		"Continue", "Goto", "Label", "ExprStatement", "Expr",
+	}
+	for _, name := range nodes {
+		if visited[name] == 0 {
+			t.Errorf("%s is not visited", name)
+		}
+	}
+}
+
+func TestInspectSkipsChildren(t *testing.T) {
+	var funcs, exprs int
+	for _, obj := range buildTree(t, walkSource) {
+		Inspect(obj, func(node models.Node) bool {
+			switch node.(type) {
+			case models.Func, *models.Func:
+				funcs++
+				// Bodies of functions are skipped.
+				return false
+			case models.Expr, *models.Expr:
+				exprs++
+			}
+			return node != nil
+		})
+	}
+	if funcs == 0 {
+		t.Fatal("functions are not visited")
+	}
+	// Expressions of global variable and red enum item are out of functions.
+	if exprs != 2 {
+		t.Errorf("want 2 expressions out of functions, got %d", exprs)
+	}
+}
@@ -6,6 +6,8 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io/fs"
 	"io/ioutil"
@@ -13,15 +15,29 @@ import (
 	"os/exec"
 	"path/filepath"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/the-xlang/xxc/ast"
+	"github.com/the-xlang/xxc/ast/models"
 	"github.com/the-xlang/xxc/documenter"
+	"github.com/the-xlang/xxc/dumper"
+	"github.com/the-xlang/xxc/formatter"
+	"github.com/the-xlang/xxc/lex"
+	"github.com/the-xlang/xxc/lsp"
 	"github.com/the-xlang/xxc/parser"
 	"github.com/the-xlang/xxc/pkg/x"
 	"github.com/the-xlang/xxc/pkg/xapi"
+	"github.com/the-xlang/xxc/pkg/xcompiler"
+	"github.com/the-xlang/xxc/pkg/xdiff"
 	"github.com/the-xlang/xxc/pkg/xio"
+	"github.com/the-xlang/xxc/pkg/xlog"
 	"github.com/the-xlang/xxc/pkg/xset"
+	"github.com/the-xlang/xxc/refactor"
+	"github.com/the-xlang/xxc/symbols"
 )
 
 type Parser = parser.Parser
@@ -30,82 +46,316 @@ const commandHelp = "help"
 const commandVersion = "version"
 const commandInit = "init"
 const commandDoc = "doc"
+const commandRun = "run"
+const commandTest = "test"
+const commandFmt = "fmt"
+const commandExplain = "explain"
+const commandFix = "fix"
+const commandAst = "ast"
+const commandLsp = "lsp"
+const commandRefs = "refs"
+const commandRename = "rename"
+const commandSymbols = "symbols"
 
 const localizationErrors = "error.json"
 const localizationWarnings = "warning.json"
+const localizationNotes = "notes.json"
+const localizationExplanations = "explanations"
+
+// Language of explanations if language is not specified or
+// explanation is not exist in the language.
+const defaultExplanationLang = "english"
 
 var helpmap = [...][2]string{
-	0: {commandHelp, "Show help."},
-	1: {commandVersion, "Show version."},
-	2: {commandInit, "Initialize new project here."},
-	3: {commandDoc, "Documentize X source code."},
+	0:  {commandHelp, "Show help."},
+	1:  {commandVersion, "Show version."},
+	2:  {commandInit, "Initialize new project here."},
+	3:  {commandDoc, "Documentize X source code."},
+	4:  {commandRun, "Transpile, compile and run X source code."},
+	5:  {commandTest, "Run tests of X package."},
+	6:  {commandFmt, "Format X source code."},
+	7:  {commandExplain, "Explain code of diagnostic."},
+	8:  {commandFix, "Apply suggested fixes of diagnostics."},
+	9:  {commandAst, "Dump syntax tree of X source code as JSON."},
+	10: {commandLsp, "Run language server over standard input and output."},
+	11: {commandRefs, "List references of definition in project."},
+	12: {commandRename, "Rename definition and its references in project."},
+	13: {commandSymbols, "List declarations of project and used standard library."},
 }
 
-func help(cmd string) {
-	if cmd != "" {
-		println("This module can only be used as single!")
-		return
+// Exit codes of xxc.
+const (
+	exitSuccess   = 0
+	exitFailure   = 1
+	exitUsage     = 2 // Same with flag package.
+	exitSettings  = 3
+	exitSource    = 4
+	exitIO        = 5
+	exitToolchain = 6
+)
+
+var exitmap = [...][2]string{
+	0: {fmt.Sprint(exitSuccess), "Success."},
+	1: {fmt.Sprint(exitFailure), "Tests are failed or source code is not formatted."},
+	2: {fmt.Sprint(exitUsage), "Invalid command-line usage."},
+	3: {fmt.Sprint(exitSettings), "X settings file is not found or has errors."},
+	4: {fmt.Sprint(exitSource), "X source code has errors."},
+	5: {fmt.Sprint(exitIO), "File couldn't read or write."},
+	6: {fmt.Sprint(exitToolchain), "C++ compiler couldn't run or failed."},
+}
+
+// Formats of symbols.
+const (
+	symbolsJson  = "json"
+	symbolsCtags = "ctags"
+)
+
+// Formats of diagnostics.
+const (
+	diagnosticsText  = "text"
+	diagnosticsJson  = "json"
+	diagnosticsSarif = "sarif"
+)
+
+// diagnosticsFormat is the output format of logs.
+var diagnosticsFormat = diagnosticsText
+
+// diagnosticsOutput is the path of file for machine readable diagnostics.
+// They are written to stderr if it is empty.
+var diagnosticsOutput = ""
+
+// diagnostics are the logs of all phases for machine readable formats.
+// They are written as one document at exit, so output of
+// programs and other outputs are never mixed with them.
+var diagnostics []xlog.CompilerLog
+
+// noColor disables colors of text diagnostics.
+var noColor = false
+
+// editorColumns enables character columns of position arguments.
+var editorColumns = false
+
+// settingsPath is the path of X settings file.
+var settingsPath = x.SettingsFile
+
+// setOverrides are the X settings overridden by command-line flags.
+// Keys are field names of xset.XSet.
+var setOverrides = map[string]string{}
+
+// setFlag is the command-line flag of X settings field.
+type setFlag string
+
+func (f setFlag) String() string { return "" }
+
+func (f setFlag) Set(value string) error {
+	field, _ := reflect.TypeOf(x.Set).Elem().FieldByName(string(f))
+	switch field.Type.Kind() {
+	case reflect.Bool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return errors.New("invalid boolean: " + value)
+		}
+	case reflect.Int:
+		if n, err := strconv.Atoi(value); err != nil || n < 0 {
+			return errors.New("invalid count: " + value)
+		}
+	}
+	setOverrides[string(f)] = value
+	return nil
+}
+
+// newFlagSet returns flag set of command with usage.
+func newFlagSet(cmd, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "Usage: xxc %s %s\n", cmd, args)
+		for _, part := range helpmap {
+			if part[0] == cmd {
+				fmt.Fprintf(out, "\n%s\n", part[1])
+				break
+			}
+		}
+		hasFlags := false
+		fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprintln(out, "\nFlags:")
+			fs.PrintDefaults()
+		}
+	}
+	return fs
+}
+
+// settingsFlag adds flag of settings file path to flag set.
+func settingsFlag(fs *flag.FlagSet) {
+	fs.StringVar(&settingsPath, "set", x.SettingsFile, "Path of X settings `file`.")
+}
+
+// setFlags adds flags to flag set for override X settings.
+func setFlags(fs *flag.FlagSet) {
+	settingsFlag(fs)
+	// Backquoted words are placeholders of flag values.
+	override := func(name, field, value string) {
+		fs.Var(setFlag(field), name, `Sets "`+keyOfSet(field)+"\" setting to `"+value+"`.")
 	}
-	max := len(helpmap[0][0])
-	for _, key := range helpmap {
-		len := len(key[0])
+	override("out-dir", "CppOutDir", "dir")
+	override("cpp-out-name", "CppOutName", "name")
+	override("out-name", "OutName", "name")
+	override("mode", "Mode", "mode")
+	override("lang", "Language", "language")
+	override("timestamp", "Timestamp", "bool")
+	override("line-directives", "LineDirectives", "bool")
+	override("warnings-as-errors", "WarningsAsErrors", "bool")
+	override("disabled-warnings", "DisabledWarnings", "keys")
+	override("max-errors", "MaxErrors", "count")
+}
+
+// positionFlags adds flags to flag set for position arguments.
+func positionFlags(fs *flag.FlagSet) {
+	const usage = "Counts column of position by characters, tab is one column.\n" +
+		"By default, columns are same as diagnostics: tab is 4 columns and\n" +
+		"other characters are as wide as bytes of UTF-8."
+	fs.BoolVar(&editorColumns, "editor-columns", false, usage)
+}
+
+// diagnosticsFlags adds flags to flag set for diagnostic outputs.
+func diagnosticsFlags(fs *flag.FlagSet) {
+	const usage = "Output `format` of diagnostics: " +
+		diagnosticsText + ", " + diagnosticsJson + " or " + diagnosticsSarif +
+		" (default " + diagnosticsText + ")."
+	fs.Func("diagnostics-format", usage, func(format string) error {
+		switch format {
+		case diagnosticsText, diagnosticsJson, diagnosticsSarif:
+			diagnosticsFormat = format
+			return nil
+		}
+		return errors.New("invalid format: " + format)
+	})
+	fs.StringVar(&diagnosticsOutput, "diagnostics-output", "",
+		"Writes "+diagnosticsJson+" and "+diagnosticsSarif+" diagnostics to `file` instead of stderr.")
+	fs.BoolVar(&noColor, "no-color", false, "Disables colors of text diagnostics.")
+}
+
+// colorful reports text diagnostics are colored or not.
+// Colors are used only if stderr is a terminal.
+func colorful() bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := os.Stderr.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// exit writes machine readable diagnostics and exits with code.
+func exit(code int) {
+	if !writeDiagnostics() && code == exitSuccess {
+		code = exitIO
+	}
+	os.Exit(code)
+}
+
+// exitErr prints message and exits with code.
+func exitErr(code int, msg string) {
+	println(msg)
+	exit(code)
+}
+
+// parseFlags parses flags of command and returns remaining arguments.
+func parseFlags(fs *flag.FlagSet, args []string) []string {
+	// Exits if has error because of flag.ExitOnError.
+	_ = fs.Parse(args)
+	return fs.Args()
+}
+
+// writeTable writes rows of two column table.
+func writeTable(sb *strings.Builder, rows [][2]string) {
+	max := len(rows[0][0])
+	for _, row := range rows {
+		len := len(row[0])
 		if len > max {
 			max = len
 		}
 	}
-	var sb strings.Builder
-	const space = 5 // Space of between command name and description.
-	for _, part := range helpmap {
-		sb.WriteString(part[0])
-		sb.WriteString(strings.Repeat(" ", (max-len(part[0]))+space))
-		sb.WriteString(part[1])
+	const space = 5 // Space of between columns.
+	for _, row := range rows {
+		sb.WriteString(row[0])
+		sb.WriteString(strings.Repeat(" ", (max-len(row[0]))+space))
+		sb.WriteString(row[1])
 		sb.WriteByte('\n')
 	}
-	println(sb.String()[:sb.Len()-1])
 }
 
-func version(cmd string) {
-	if cmd != "" {
-		println("This module can only be used as single!")
-		return
+func help(args []string) {
+	fs := newFlagSet(commandHelp, "")
+	if len(parseFlags(fs, args)) > 0 {
+		exitErr(exitUsage, "This module can only be used as single!")
+	}
+	var sb strings.Builder
+	sb.WriteString("Usage: xxc [flags] <path>\n")
+	sb.WriteString("       xxc <command> [flags] [arguments]\n\n")
+	sb.WriteString("Commands:\n")
+	writeTable(&sb, helpmap[:])
+	sb.WriteString("\nExit codes:\n")
+	writeTable(&sb, exitmap[:])
+	sb.WriteString("\nUse \"xxc <command> -h\" for more information about a command.")
+	println(sb.String())
+}
+
+func version(args []string) {
+	fs := newFlagSet(commandVersion, "")
+	if len(parseFlags(fs, args)) > 0 {
+		exitErr(exitUsage, "This module can only be used as single!")
 	}
 	println("xxc version", x.Version)
 }
 
-func initProject(cmd string) {
-	if cmd != "" {
-		println("This module can only be used as single!")
-		return
+func initProject(args []string) {
+	fs := newFlagSet(commandInit, "[flags]")
+	settingsFlag(fs)
+	if len(parseFlags(fs, args)) > 0 {
+		exitErr(exitUsage, "This module can only be used as single!")
 	}
 	bytes, err := json.MarshalIndent(*xset.Default, "", "\t")
 	if err != nil {
-		println(err)
-		os.Exit(0)
+		exitErr(exitIO, err.Error())
 	}
-	err = ioutil.WriteFile(x.SettingsFile, bytes, 0666)
+	err = ioutil.WriteFile(settingsPath, bytes, 0666)
 	if err != nil {
-		println(err.Error())
-		os.Exit(0)
+		exitErr(exitIO, err.Error())
 	}
 	println("Initialized project.")
 }
 
-func doc(cmd string) {
-	cmd = strings.TrimSpace(cmd)
-	paths := strings.SplitN(cmd, " ", -1)
+func doc(args []string) {
+	fs := newFlagSet(commandDoc, "[flags] <path>...")
+	setFlags(fs)
+	diagnosticsFlags(fs)
+	paths := parseFlags(fs, args)
+	if len(paths) == 0 {
+		exitErr(exitUsage, "Path of X source code is missing!")
+	}
+	// Continues with other paths on failure,
+	// but exits with code of last failure.
+	code := exitSuccess
 	for _, path := range paths {
-		path = strings.TrimSpace(path)
 		p := compile(path, false, true, true)
 		if p == nil {
+			code = exitIO
 			continue
 		}
-		if printlogs(p) {
-			fmt.Println(x.GetError("doc_couldnt_generated", path))
+		if len(p.Errors) > 0 {
+			p.PushErr("doc_couldnt_generated", path)
+			printlogs(p)
+			code = exitSource
 			continue
 		}
+		printlogs(p)
 		docjson, err := documenter.Doc(p)
 		if err != nil {
-			fmt.Println(x.GetError("error", err.Error()))
+			printcompilerlogs([]xlog.CompilerLog{{
+				Type:    xlog.FlatError,
+				Message: err.Error(),
+			}})
+			code = exitIO
 			continue
 		}
 		// Remove SrcExt from path
@@ -113,18 +363,668 @@ func doc(cmd string) {
 		path = filepath.Join(x.Set.CppOutDir, path+x.DocExt)
 		writeOutput(path, docjson)
 	}
+	exit(code)
+}
+
+// codeKey returns key and message of diagnostic code.
+// Reports false if code is not exist.
+func codeKey(code string) (key, msg string, ok bool) {
+	for key, ecode := range x.ErrorCodes {
+		if ecode == code {
+			return key, x.Errors[key], true
+		}
+	}
+	for key, wcode := range x.WarningCodes {
+		if wcode == code {
+			return key, x.Warnings[key], true
+		}
+	}
+	return "", "", false
+}
+
+// formatVerb matches verbs of formatted messages such as %s.
+var formatVerb = regexp.MustCompile(`%[a-z]`)
+
+// readExplanation returns explanation of code in language.
+// Explanation in default language is returned if not exist in language.
+func readExplanation(lang, code string) (string, bool) {
+	for _, lang := range [...]string{lang, defaultExplanationLang} {
+		path := filepath.Join(x.LangsPath, lang, localizationExplanations, code+".md")
+		bytes, err := os.ReadFile(path)
+		if err == nil {
+			return string(bytes), true
+		}
+	}
+	return "", false
+}
+
+func explain(args []string) {
+	fs := newFlagSet(commandExplain, "[flags] <code>")
+	lang := fs.String("lang", defaultExplanationLang, "Language of explanation.")
+	args = parseFlags(fs, args)
+	switch len(args) {
+	case 0:
+		exitErr(exitUsage, "Code of diagnostic is missing!")
+	case 1:
+	default:
+		exitErr(exitUsage, "Only one code can be explained at once!")
+	}
+	code := strings.ToUpper(args[0])
+	// Keys of diagnostics are accepted too.
+	if ecode, ok := x.ErrorCodes[args[0]]; ok {
+		code = ecode
+	} else if wcode, ok := x.WarningCodes[args[0]]; ok {
+		code = wcode
+	}
+	key, msg, ok := codeKey(code)
+	if !ok {
+		exitErr(exitUsage, `Unknown diagnostic code: "`+args[0]+`"`)
+	}
+	fmt.Print(explanation(*lang, code, key, msg))
+}
+
+// explanation returns explanation of code in language.
+// Explanation is made of key and message of code if not exist.
+func explanation(lang, code, key, msg string) string {
+	if text, ok := readExplanation(lang, code); ok {
+		return text
+	}
+	// Arguments of message are unknown.
+	msg = formatVerb.ReplaceAllString(msg, "…")
+	return fmt.Sprintf("# %s: %s\n\nThere is no detailed explanation for this code yet.\nKey of diagnostic is %s.\n", code, msg, key)
+}
+
+// fixSource applies suggested fixes of diagnostics of X source code.
+// Files are not rewritten if dryRun is true, diffs are printed instead.
+// Only first fix of each diagnostic is applied and conflicting fixes
+// are skipped, so running again may fix more.
+// Returns exit code.
+func fixSource(path string, dryRun bool) int {
+	p := compile(path, true, false, false)
+	if p == nil {
+		return exitIO
+	}
+	logs := make([]xlog.CompilerLog, 0, len(p.Warnings)+len(p.Errors))
+	logs = append(logs, p.Warnings...)
+	logs = append(logs, p.Errors...)
+	sources, err := xlog.ApplyFixes(logs)
+	if err != nil {
+		println(err.Error())
+		return exitIO
+	}
+	for _, src := range sources {
+		if dryRun {
+			fmt.Print(xdiff.Unified(src.Path+".orig", src.Path, src.Old, src.New))
+			continue
+		}
+		writeOutput(src.Path, src.New)
+		fmt.Println(src.Path)
+	}
+	return exitSuccess
+}
+
+func fix(args []string) {
+	fs := newFlagSet(commandFix, "[flags] <path>")
+	setFlags(fs)
+	dryRun := fs.Bool("dry-run", false, "Print diffs of fixes, don't rewrite files.")
+	args = parseFlags(fs, args)
+	switch len(args) {
+	case 0:
+		exitErr(exitUsage, "Path of X source code is missing!")
+	case 1:
+	default:
+		exitErr(exitUsage, "Only one source file can be fixed at once!")
+	}
+	exit(fixSource(args[0], *dryRun))
+}
+
+// dumpSource prints JSON of syntax tree of X source file.
+// Tokens of lexer are printed too if toks is true.
+// Returns exit code.
+func dumpSource(path string, toks bool) int {
+	f, err := xio.Openfx(path)
+	if err != nil {
+		println(err.Error())
+		return exitIO
+	}
+	l := lex.NewLex(f)
+	ltoks := l.Lex()
+	if len(l.Logs) > 0 {
+		printcompilerlogs(l.Logs)
+		return exitSource
+	}
+	b := ast.NewBuilder(ltoks)
+	b.Build()
+	if len(b.Errors) > 0 {
+		printcompilerlogs(b.Errors)
+		return exitSource
+	}
+	if !toks {
+		ltoks = nil
+	}
+	out, err := dumper.Dump(path, ltoks, b.Tree)
+	if err != nil {
+		println(err.Error())
+		return exitIO
+	}
+	fmt.Println(out)
+	return exitSuccess
+}
+
+func dumpAst(args []string) {
+	fs := newFlagSet(commandAst, "[flags] <path>")
+	diagnosticsFlags(fs)
+	toks := fs.Bool("tokens", false, "Dump tokens of lexer too.")
+	args = parseFlags(fs, args)
+	switch len(args) {
+	case 0:
+		exitErr(exitUsage, "Path of X source code is missing!")
+	case 1:
+	default:
+		exitErr(exitUsage, "Only one source file can be dumped at once!")
+	}
+	exit(dumpSource(args[0], *toks))
+}
+
+func languageServer(args []string) {
+	fs := newFlagSet(commandLsp, "[flags]")
+	setFlags(fs)
+	if len(parseFlags(fs, args)) > 0 {
+		exitErr(exitUsage, "Language server does not takes arguments!")
+	}
+	server := lsp.New(os.Stdin, os.Stdout)
+	server.LoadSettings = loadServerSettings
+	// Settings file of documents is searched if it is not given.
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "set" {
+			server.SettingsFile, _ = filepath.Abs(settingsPath)
+		}
+	})
+	err := server.Serve()
+	if err != nil {
+		exitErr(exitFailure, err.Error())
+	}
+}
+
+// parsePosition parses position argument as path:row:column.
+func parsePosition(arg string) (path string, row, column int, ok bool) {
+	parts := strings.Split(arg, ":")
+	if len(parts) < 3 {
+		return "", 0, 0, false
+	}
+	n := len(parts)
+	row, err := strconv.Atoi(parts[n-2])
+	if err != nil || row < 1 {
+		return "", 0, 0, false
+	}
+	column, err = strconv.Atoi(parts[n-1])
+	if err != nil || column < 1 {
+		return "", 0, 0, false
+	}
+	return strings.Join(parts[:n-2], ":"), row, column, true
+}
+
+// positionArg returns position of argument, exits if it is invalid.
+// Column is converted to column of diagnostics if editorColumns is true.
+func positionArg(arg string) (path string, row, column int) {
+	path, row, column, ok := parsePosition(arg)
+	if !ok {
+		exitErr(exitUsage, "Invalid position: "+arg+", expected <file:row:column>!")
+	}
+	if editorColumns {
+		column = diagnosticColumn(path, row, column)
+	}
+	return path, row, column
+}
+
+// diagnosticColumn returns column of diagnostics of character column.
+// Character column counts each character as one column.
+// Diagnostics columns are same as lexer, tabs are wide as 4 columns
+// and others are wide as bytes of UTF-8.
+func diagnosticColumn(path string, row, column int) int {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		// File errors are reported by compilation.
+		return column
+	}
+	lines := strings.Split(string(bytes), "\n")
+	if row > len(lines) {
+		return column
+	}
+	col := 1
+	for _, r := range lines[row-1] {
+		if column <= 1 {
+			break
+		}
+		if r == '\t' {
+			col += 4
+		} else {
+			col += utf8.RuneLen(r)
+		}
+		column--
+	}
+	// Column may be after of line.
+	return col + column - 1
+}
+
+// listRefs prints locations of references of definition at position.
+// Returns exit code.
+func listRefs(path string, row, column int) int {
+	_, locs, logs, err := refactor.Refs(path, row, column)
+	switch {
+	case logs != nil:
+		printcompilerlogs(logs)
+		return exitSource
+	case err != nil:
+		println(err.Error())
+		return exitFailure
+	}
+	for _, loc := range locs {
+		fmt.Println(loc)
+	}
+	return exitSuccess
+}
+
+func refs(args []string) {
+	fs := newFlagSet(commandRefs, "[flags] <file:row:column>")
+	setFlags(fs)
+	diagnosticsFlags(fs)
+	positionFlags(fs)
+	args = parseFlags(fs, args)
+	switch len(args) {
+	case 0:
+		exitErr(exitUsage, "Position of identifier is missing!")
+	case 1:
+	default:
+		exitErr(exitUsage, "Only one identifier can be searched at once!")
+	}
+	path, row, column := positionArg(args[0])
+	loadXSet()
+	exit(listRefs(path, row, column))
+}
+
+// renameRefs renames definition at position and its references.
+// Files are not rewritten if dryRun is true, diffs are printed instead.
+// Returns exit code.
+func renameRefs(path string, row, column int, id string, dryRun bool) int {
+	sources, logs, err := refactor.Rename(path, row, column, id)
+	if logs != nil {
+		// Logs of renamed code are shown with renamed sources.
+		if err != nil {
+			for _, src := range sources {
+				xio.Overlay[src.Path] = src.New
+			}
+		}
+		printcompilerlogs(logs)
+	}
+	switch {
+	case err != nil:
+		println(err.Error())
+		return exitFailure
+	case logs != nil:
+		return exitSource
+	}
+	for _, src := range sources {
+		if dryRun {
+			fmt.Print(xdiff.Unified(src.Path+".orig", src.Path, src.Old, src.New))
+			continue
+		}
+		writeOutput(src.Path, src.New)
+		fmt.Println(src.Path)
+	}
+	return exitSuccess
+}
+
+func rename(args []string) {
+	fs := newFlagSet(commandRename, "[flags] <file:row:column> <name>")
+	setFlags(fs)
+	diagnosticsFlags(fs)
+	positionFlags(fs)
+	dryRun := fs.Bool("dry-run", false, "Print diffs of renaming, don't rewrite files.")
+	args = parseFlags(fs, args)
+	if len(args) != 2 {
+		exitErr(exitUsage, "Position of identifier and new name are required!")
+	}
+	path, row, column := positionArg(args[0])
+	loadXSet()
+	exit(renameRefs(path, row, column, args[1], *dryRun))
+}
+
+// printSymbols prints declarations of project by format.
+// Returns exit code.
+func printSymbols(format string) int {
+	decls, logs, err := symbols.Project()
+	switch {
+	case logs != nil:
+		printcompilerlogs(logs)
+		return exitSource
+	case err != nil:
+		println(err.Error())
+		return exitIO
+	}
+	if format == symbolsCtags {
+		fmt.Print(symbols.Ctags(decls, x.ProjectPath))
+		return exitSuccess
+	}
+	out, err := symbols.JSON(decls, x.ProjectPath)
+	if err != nil {
+		println(err.Error())
+		return exitIO
+	}
+	fmt.Println(out)
+	return exitSuccess
+}
+
+func listSymbols(args []string) {
+	fs := newFlagSet(commandSymbols, "[flags]")
+	setFlags(fs)
+	diagnosticsFlags(fs)
+	format := symbolsJson
+	const usage = "Output `format` of symbols: " +
+		symbolsJson + " or " + symbolsCtags + " (default " + symbolsJson + ")."
+	fs.Func("format", usage, func(value string) error {
+		switch value {
+		case symbolsJson, symbolsCtags:
+			format = value
+			return nil
+		}
+		return errors.New("invalid format: " + value)
+	})
+	if len(parseFlags(fs, args)) > 0 {
+		exitErr(exitUsage, "Symbols command does not takes arguments!")
+	}
+	loadXSet()
+	exit(printSymbols(format))
+}
+
+// runProgram transpiles and compiles X source code into temporary
+// directory and runs it with given arguments.
+// Returns exit code of program.
+func runProgram(path string, args []string) int {
+	p := compile(path, true, false, false)
+	if p == nil {
+		return exitIO
+	}
+	if printlogs(p) {
+		return exitSource
+	}
+	mapCompilerLogs()
+	cxx := p.Cpp()
+	appendStandard(&cxx)
+	dir, err := os.MkdirTemp("", "xxc-run-")
+	if err != nil {
+		println(err.Error())
+		return exitIO
+	}
+	defer os.RemoveAll(dir)
+	cppPath := filepath.Join(dir, x.Set.CppOutName)
+	writeCpp(cppPath, cxx)
+	out := filepath.Join(dir, x.Set.OutName)
+	logs, ok := xcompiler.Compile(x.Set, cppPath, out)
+	printcompilerlogs(logs)
+	if !ok {
+		return exitToolchain
+	}
+	program := exec.Command(out, args...)
+	program.Stdin = os.Stdin
+	program.Stdout = os.Stdout
+	program.Stderr = os.Stderr
+	err = program.Run()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitErr.ExitCode()
+		}
+		println(err.Error())
+		return exitIO
+	}
+	return exitSuccess
+}
+
+func run(args []string) {
+	fs := newFlagSet(commandRun, "[flags] <path> [-- arguments...]")
+	setFlags(fs)
+	diagnosticsFlags(fs)
+	args = parseFlags(fs, args)
+	if len(args) == 0 {
+		exitErr(exitUsage, "Path of X source code is missing!")
+	}
+	path := args[0]
+	args = args[1:]
+	if len(args) > 0 {
+		if args[0] != "--" {
+			exitErr(exitUsage, "Program arguments must be after the \"--\" argument!")
+		}
+		args = args[1:]
+	}
+	exit(runProgram(path, args))
+}
+
+// testFiles returns paths of useable test files in directory.
+func testFiles(dir string) ([]string, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() ||
+			!strings.HasSuffix(name, x.SrcExt) ||
+			!xio.IsUseable(name) ||
+			!xio.IsTest(name) {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, name))
+	}
+	return paths, nil
+}
+
+// runTests transpiles and compiles tests of package in directory
+// into temporary directory and runs them.
+// Returns exit code of tests.
+func runTests(dir string) int {
+	paths, err := testFiles(dir)
+	if err != nil {
+		println(err.Error())
+		return exitIO
+	}
+	if len(paths) == 0 {
+		println(`No test files found in "` + dir + `"!`)
+		return exitSuccess
+	}
+	// Other files of package are parsed as local package.
+	p, ok := newParser(paths[0])
+	if p == nil {
+		return exitIO
+	}
+	if ok {
+		p.Test = true
+		p.Parsef(false, false)
+	}
+	if printlogs(p) {
+		return exitSource
+	}
+	if !p.HasTests() {
+		println(`No tests found in "` + dir + `"!`)
+		return exitSuccess
+	}
+	mapCompilerLogs()
+	cxx := p.Cpp()
+	appendStandard(&cxx)
+	tmp, err := os.MkdirTemp("", "xxc-test-")
+	if err != nil {
+		println(err.Error())
+		return exitIO
+	}
+	defer os.RemoveAll(tmp)
+	cppPath := filepath.Join(tmp, x.Set.CppOutName)
+	writeCpp(cppPath, cxx)
+	out := filepath.Join(tmp, x.Set.OutName)
+	// Tests are have own entry point in API.
+	set := *x.Set
+	set.CompilerFlags = append([]string{"-D" + xapi.TestMacro}, set.CompilerFlags...)
+	logs, ok := xcompiler.Compile(&set, cppPath, out)
+	printcompilerlogs(logs)
+	if !ok {
+		return exitToolchain
+	}
+	tests := exec.Command(out)
+	tests.Stdin = os.Stdin
+	tests.Stdout = os.Stdout
+	tests.Stderr = os.Stderr
+	err = tests.Run()
+	if err != nil {
+		// Test runner exits with failure if tests are failed. Other exit
+		// codes are from panics out of tests such as initializers, they
+		// are not passed through to not collide with exit codes of xxc.
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			if exitErr.ExitCode() != exitFailure {
+				println("FAIL: test runner is exited with " + exitErr.String())
+			}
+			return exitFailure
+		}
+		println(err.Error())
+		return exitIO
+	}
+	return exitSuccess
+}
+
+func test(args []string) {
+	fs := newFlagSet(commandTest, "[flags] [directory]")
+	setFlags(fs)
+	diagnosticsFlags(fs)
+	args = parseFlags(fs, args)
+	dir := "."
+	switch len(args) {
+	case 0:
+	case 1:
+		dir = args[0]
+	default:
+		exitErr(exitUsage, "Only one package can be tested at once!")
+	}
+	exit(runTests(dir))
 }
 
-func processCommand(namespace, cmd string) bool {
+// sourceFiles returns paths of X source files of path.
+// Directories are walked recursively.
+func sourceFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	var paths []string
+	err = filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, x.SrcExt) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths, err
+}
+
+// formatFile formats X source file.
+// File is not rewritten if check or diff is true.
+// Returns changed as true if file is not formatted.
+func formatFile(path string, check, diff bool) (changed bool, code int) {
+	f, err := xio.Openfx(path)
+	if err != nil {
+		println(err.Error())
+		return false, exitIO
+	}
+	indent := strings.Repeat(x.Set.Indent, x.Set.IndentCount)
+	src, logs := formatter.Format(f, indent)
+	if logs != nil {
+		printcompilerlogs(logs)
+		return false, exitSource
+	}
+	old := string(f.Data)
+	if src == old {
+		return false, exitSuccess
+	}
+	switch {
+	case diff:
+		fmt.Print(xdiff.Unified(path+".orig", path, old, src))
+	case check:
+		fmt.Println(path)
+	default:
+		writeOutput(path, src)
+	}
+	return true, exitSuccess
+}
+
+func format(args []string) {
+	fs := newFlagSet(commandFmt, "[flags] [path...]")
+	settingsFlag(fs)
+	diagnosticsFlags(fs)
+	check := fs.Bool("check", false, "List files which are not formatted and exit with failure, don't rewrite files.")
+	diff := fs.Bool("diff", false, "Print diffs of formatting and exit with failure if any, don't rewrite files.")
+	paths := parseFlags(fs, args)
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	loadXSet()
+	// Continues with other files on failure,
+	// but exits with code of last failure.
+	code := exitSuccess
+	changed := false
+	for _, path := range paths {
+		files, err := sourceFiles(path)
+		if err != nil {
+			println(err.Error())
+			code = exitIO
+			continue
+		}
+		for _, file := range files {
+			fchanged, fcode := formatFile(file, *check, *diff)
+			changed = changed || fchanged
+			if fcode != exitSuccess {
+				code = fcode
+			}
+		}
+	}
+	// Unformatted files are failure like diff(1) if not rewritten.
+	if code == exitSuccess && changed && (*check || *diff) {
+		code = exitFailure
+	}
+	exit(code)
+}
+
+func processCommand(namespace string, args []string) bool {
 	switch namespace {
 	case commandHelp:
-		help(cmd)
+		help(args)
 	case commandVersion:
-		version(cmd)
+		version(args)
 	case commandInit:
-		initProject(cmd)
+		initProject(args)
 	case commandDoc:
-		doc(cmd)
+		doc(args)
+	case commandRun:
+		run(args)
+	case commandTest:
+		test(args)
+	case commandFmt:
+		format(args)
+	case commandExplain:
+		explain(args)
+	case commandFix:
+		fix(args)
+	case commandAst:
+		dumpAst(args)
+	case commandLsp:
+		languageServer(args)
+	case commandRefs:
+		refs(args)
+	case commandRename:
+		rename(args)
+	case commandSymbols:
+		listSymbols(args)
 	default:
 		return false
 	}
@@ -134,8 +1034,7 @@ func processCommand(namespace, cmd string) bool {
 func init() {
 	execp, err := os.Executable()
 	if err != nil {
-		println(err.Error())
-		os.Exit(0)
+		exitErr(exitIO, err.Error())
 	}
 	execp = filepath.Dir(execp)
 	x.ExecPath = execp
@@ -147,20 +1046,11 @@ func init() {
 	// Not started with arguments.
 	// Here is "2" but "os.Args" always have one element for store working directory.
 	if len(os.Args) < 2 {
-		os.Exit(0)
+		help(nil)
+		exit(exitUsage)
 	}
-	var sb strings.Builder
-	for _, arg := range os.Args[1:] {
-		sb.WriteString(" " + arg)
-	}
-	os.Args[0] = sb.String()[1:]
-	arg := os.Args[0]
-	i := strings.Index(arg, " ")
-	if i == -1 {
-		i = len(arg)
-	}
-	if processCommand(arg[:i], arg[i:]) {
-		os.Exit(0)
+	if processCommand(os.Args[1], os.Args[2:]) {
+		exit(exitSuccess)
 	}
 }
 
@@ -191,6 +1081,33 @@ func loadLangWarns(path string, infos []fs.FileInfo) {
 	}
 }
 
+func loadLangNotes(path string, infos []fs.FileInfo) {
+	i := -1
+	for j, f := range infos {
+		if f.IsDir() || f.Name() != localizationNotes {
+			continue
+		}
+		i = j
+		path = filepath.Join(path, f.Name())
+		break
+	}
+	if i == -1 {
+		return
+	}
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		println("Language's notes couldn't loaded (uses default);")
+		println(err.Error())
+		return
+	}
+	err = json.Unmarshal(bytes, &x.Notes)
+	if err != nil {
+		println("Language's notes couldn't loaded (uses default);")
+		println(err.Error())
+		return
+	}
+}
+
 func loadLangErrs(path string, infos []fs.FileInfo) {
 	i := -1
 	for j, f := range infos {
@@ -232,113 +1149,326 @@ func loadLang() {
 	}
 	loadLangWarns(path, infos)
 	loadLangErrs(path, infos)
+	loadLangNotes(path, infos)
+}
+
+// keyOfSet returns JSON key of XSet field.
+func keyOfSet(field string) string {
+	key, _ := reflect.TypeOf(x.Set).Elem().FieldByName(field)
+	tag := string(key.Tag)
+	// 6 for skip "json:
+	return tag[6 : len(tag)-1]
 }
 
 func checkMode() {
 	lower := strings.ToLower(x.Set.Mode)
 	if lower != xset.ModeTranspile &&
 		lower != xset.ModeCompile {
-		key, _ := reflect.TypeOf(x.Set).Elem().FieldByName("Mode")
-		tag := string(key.Tag)
-		// 6 for skip "json:
-		tag = tag[6 : len(tag)-1]
-		println(x.GetError("invalid_value_for_key", x.Set.Mode, tag))
-		os.Exit(0)
+		settingsErr("invalid_value_for_key", x.Set.Mode, keyOfSet("Mode"))
 	}
 	x.Set.Mode = lower
 }
 
+func checkCompiler() {
+	if x.Set.Mode != xset.ModeCompile {
+		return
+	}
+	x.Set.Compiler = strings.TrimSpace(x.Set.Compiler)
+	if x.Set.Compiler == "" {
+		settingsErr("invalid_value_for_key", x.Set.Compiler, keyOfSet("Compiler"))
+	}
+}
+
+func checkDisabledWarnings() {
+	for _, key := range x.Set.DisabledWarnings {
+		if _, ok := x.Warnings[key]; !ok {
+			settingsErr("undefined_warning_key", key)
+		}
+	}
+}
+
+// overrideXSet applies command-line overrides to X settings.
+func overrideXSet() {
+	set := reflect.ValueOf(x.Set).Elem()
+	for field, value := range setOverrides {
+		field := set.FieldByName(field)
+		switch field.Kind() {
+		case reflect.Bool:
+			// Value is checked by flag already.
+			b, _ := strconv.ParseBool(value)
+			field.SetBool(b)
+		case reflect.Int:
+			n, _ := strconv.Atoi(value)
+			field.SetInt(int64(n))
+		case reflect.Slice:
+			// Lists are separated by comma.
+			var list []string
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					list = append(list, item)
+				}
+			}
+			field.Set(reflect.ValueOf(list))
+		default:
+			field.SetString(value)
+		}
+	}
+}
+
+// settingsErr prints error of X settings and exits.
+func settingsErr(key string, args ...any) {
+	printcompilerlogs([]xlog.CompilerLog{{
+		Type:    xlog.FlatError,
+		Message: x.GetError(key, args...),
+		Key:     key,
+		Args:    args,
+	}})
+	exit(exitSettings)
+}
+
+// settingsSyntaxErr prints syntax error of X settings and exits.
+func settingsSyntaxErr(bytes []byte, err error) {
+	var offset int64
+	switch t := err.(type) {
+	case *json.SyntaxError:
+		offset = t.Offset
+	case *json.UnmarshalTypeError:
+		offset = t.Offset
+	default:
+		settingsErr("settings_has_errors", err.Error())
+	}
+	log := xlog.CompilerLog{
+		Type:    xlog.Error,
+		Row:     1,
+		Column:  1,
+		Path:    settingsPath,
+		Message: x.GetError("settings_has_errors", err.Error()),
+		Key:     "settings_has_errors",
+		Args:    []any{err.Error()},
+	}
+	// Offset is the count of bytes read before error.
+	for _, b := range bytes[:offset] {
+		if b == '\n' {
+			log.Row++
+			log.Column = 1
+		} else {
+			log.Column++
+		}
+	}
+	printcompilerlogs([]xlog.CompilerLog{log})
+	exit(exitSettings)
+}
+
 func loadXSet() {
 	// File check.
-	info, err := os.Stat(x.SettingsFile)
+	info, err := os.Stat(settingsPath)
 	if err != nil || info.IsDir() {
-		println(`X settings file ("` + x.SettingsFile + `") is not found!`)
-		os.Exit(0)
+		settingsErr("settings_not_found", settingsPath)
 	}
-	bytes, err := os.ReadFile(x.SettingsFile)
+	bytes, err := os.ReadFile(settingsPath)
 	if err != nil {
-		println(err.Error())
-		os.Exit(0)
+		settingsErr("settings_has_errors", err.Error())
 	}
 	x.Set, err = xset.Load(bytes)
 	if err != nil {
-		println("X settings has errors;")
-		println(err.Error())
-		os.Exit(0)
+		settingsSyntaxErr(bytes, err)
 	}
+	overrideXSet()
+	x.ProjectPath, _ = filepath.Abs(filepath.Dir(settingsPath))
 	loadLang()
 	checkMode()
+	checkCompiler()
+	checkDisabledWarnings()
+}
+
+// loadServerSettings loads settings file of document for language server.
+// Default settings are used if path is empty or settings has errors.
+func loadServerSettings(path string) error {
+	set := *xset.Default
+	x.Set = &set
+	var err error
+	if path != "" {
+		var bytes []byte
+		bytes, err = os.ReadFile(path)
+		if err == nil {
+			var loaded *xset.XSet
+			loaded, err = xset.Load(bytes)
+			if err == nil {
+				x.Set = loaded
+			}
+		}
+	}
+	overrideXSet()
+	loadLang()
+	return err
 }
 
 // printlogs prints logs and returns true
 // if logs has error, false if not.
 func printlogs(p *Parser) bool {
-	var str strings.Builder
-	for _, log := range p.Warnings {
-		str.WriteString(log.String())
-		str.WriteByte('\n')
+	logs := make([]xlog.CompilerLog, 0, len(p.Warnings)+len(p.Errors))
+	logs = append(logs, p.Warnings...)
+	logs = append(logs, p.Errors...)
+	printcompilerlogs(logs)
+	return len(p.Errors) > 0
+}
+
+// limitErrors returns logs with errors at most max_errors setting.
+// Omitted errors are reported by an error at end of logs.
+func limitErrors(logs []xlog.CompilerLog) []xlog.CompilerLog {
+	// Settings may be not loaded yet for errors of settings.
+	if x.Set == nil || x.Set.MaxErrors <= 0 {
+		return logs
+	}
+	limited := make([]xlog.CompilerLog, 0, len(logs))
+	count := 0
+	for _, log := range logs {
+		if log.IsError() {
+			count++
+			if count > x.Set.MaxErrors {
+				continue
+			}
+		}
+		limited = append(limited, log)
+	}
+	if omitted := count - x.Set.MaxErrors; omitted > 0 {
+		limited = append(limited, xlog.CompilerLog{
+			Type:    xlog.FlatError,
+			Message: x.GetError("too_many_errors", omitted),
+			Key:     "too_many_errors",
+			Args:    []any{omitted},
+		})
+	}
+	return limited
+}
+
+// printcompilerlogs prints logs by diagnostics format.
+// Logs of machine readable formats are collected for writeDiagnostics.
+func printcompilerlogs(logs []xlog.CompilerLog) {
+	if diagnosticsFormat != diagnosticsText {
+		diagnostics = append(diagnostics, logs...)
+		return
 	}
-	for _, log := range p.Errors {
-		str.WriteString(log.String())
+	logs = limitErrors(logs)
+	var str strings.Builder
+	color := colorful()
+	for _, log := range logs {
+		str.WriteString(log.Pretty(color))
 		str.WriteByte('\n')
 	}
 	print(str.String())
-	return len(p.Errors) > 0
+}
+
+// writeDiagnostics writes collected logs as one document of
+// machine readable format to diagnostics output or stderr.
+// Document is written even if there is no log, so tools can
+// read diagnostics of every run. Returns false if writing failed.
+func writeDiagnostics() bool {
+	var out string
+	var err error
+	switch diagnosticsFormat {
+	case diagnosticsJson:
+		out, err = xlog.JSON(limitErrors(diagnostics))
+	case diagnosticsSarif:
+		out, err = xlog.SARIF(limitErrors(diagnostics))
+	default:
+		return true
+	}
+	diagnostics = nil
+	if err == nil {
+		out += "\n"
+		if diagnosticsOutput == "" {
+			_, err = os.Stderr.WriteString(out)
+		} else {
+			err = ioutil.WriteFile(diagnosticsOutput, []byte(out), 0o666)
+		}
+	}
+	if err != nil {
+		println(err.Error())
+		return false
+	}
+	return true
 }
 
 func appendStandard(code *string) {
-	year, month, day := time.Now().Date()
-	hour, min, _ := time.Now().Clock()
-	timeStr := fmt.Sprintf("%d/%d/%d %d.%d (DD/MM/YYYY) (HH.MM)",
-		day, month, year, hour, min)
 	var sb strings.Builder
 	sb.WriteString("// Auto generated by XXC.\n")
 	sb.WriteString("// XXC version: ")
 	sb.WriteString(x.Version)
 	sb.WriteByte('\n')
-	sb.WriteString("// Date: ")
-	sb.WriteString(timeStr)
-	sb.WriteString("\n\n#include \"")
+	// Timestamp breaks reproducibility of output, so it's optional.
+	if x.Set.Timestamp {
+		year, month, day := time.Now().Date()
+		hour, min, _ := time.Now().Clock()
+		timeStr := fmt.Sprintf("%d/%d/%d %d.%d (DD/MM/YYYY) (HH.MM)",
+			day, month, year, hour, min)
+		sb.WriteString("// Date: ")
+		sb.WriteString(timeStr)
+		sb.WriteByte('\n')
+	}
+	sb.WriteString("\n#include \"")
 	sb.WriteString(xapi.XXCHeader)
 	sb.WriteString("\"\n\n")
 	sb.WriteString(*code)
 	*code = sb.String()
 }
 
+// writeCpp writes generated cpp code to path. Marks of generated code
+// are resolved to line directives of path.
+func writeCpp(path, cxx string) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	writeOutput(path, models.ResolveLineDirectives(cxx, abs))
+}
+
 func writeOutput(path, content string) {
 	dir := filepath.Dir(path)
 	err := os.MkdirAll(dir, 0o777)
 	if err != nil {
-		println(err.Error())
-		os.Exit(0)
+		exitErr(exitIO, err.Error())
 	}
 	bytes := []byte(content)
 	err = ioutil.WriteFile(path, bytes, 0o666)
 	if err != nil {
-		println(err.Error())
-		os.Exit(0)
+		exitErr(exitIO, err.Error())
 	}
 }
 
-func compile(path string, main, nolocal, justDefs bool) *Parser {
+// newParser returns new parser for X source file.
+// Returns ok as false if parser has errors and not ready for parse.
+//
+// Special case is;
+//
+//	newParser(path) -> nil, false if file couldn't opened
+func newParser(path string) (p *Parser, ok bool) {
 	loadXSet()
-	p := parser.New(nil)
+	p = parser.New(nil)
 	f, err := xio.Openfx(path)
 	if err != nil {
 		println(err.Error())
-		return nil
+		return nil, false
 	}
 	if !xio.IsUseable(path) {
 		p.PushErr("file_not_useable")
-		return p
+		return p, false
 	}
 	// Check standard library.
 	inf, err := os.Stat(x.StdlibPath)
 	if err != nil || !inf.IsDir() {
 		p.PushErr("no_stdlib")
-		return p
+		return p, false
 	}
 	p.File = f
+	return p, true
+}
+
+func compile(path string, main, nolocal, justDefs bool) *Parser {
+	p, ok := newParser(path)
+	if !ok {
+		return p
+	}
 	p.NoLocalPkg = nolocal
 	p.Parsef(main, justDefs)
 	return p
@@ -355,27 +1485,65 @@ func execPostCommands() {
 	}
 }
 
-func doSpell(path, cxx string) {
-	defer execPostCommands()
-	writeOutput(path, cxx)
+// mapCompilerLogs enables line directives for generated cpp code
+// which is only compiled, so logs of C++ compiler are mapped to X source code.
+func mapCompilerLogs() { x.Set.LineDirectives = true }
+
+// compileCpp compiles generated cpp code to executable.
+// Returns true if success, false if not.
+func compileCpp(path string) bool {
+	out := filepath.Join(x.Set.CppOutDir, x.Set.OutName)
+	logs, ok := xcompiler.Compile(x.Set, path, out)
+	printcompilerlogs(logs)
+	return ok
+}
+
+// doSpell writes cpp code, compiles it if mode is compile
+// and executes post commands. Returns exit code.
+func doSpell(path, cxx string) int {
+	writeCpp(path, cxx)
 	switch x.Set.Mode {
 	case xset.ModeCompile:
 		defer os.Remove(path)
-		println("compilation is not supported yet")
+		if !compileCpp(path) {
+			return exitToolchain
+		}
 	}
+	execPostCommands()
+	return exitSuccess
 }
 
 func main() {
-	fpath := os.Args[0]
+	fs := flag.NewFlagSet("xxc", flag.ExitOnError)
+	setFlags(fs)
+	diagnosticsFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: xxc [flags] <path>\n\nFlags:")
+		fs.PrintDefaults()
+		fmt.Fprintln(fs.Output(), "\nRun \"xxc help\" for list of commands.")
+	}
+	args := parseFlags(fs, os.Args[1:])
+	switch len(args) {
+	case 0:
+		exitErr(exitUsage, "Path of X source code is missing!")
+	case 1:
+	default:
+		exitErr(exitUsage, "Only one X source code can be transpiled at once!")
+	}
+	fpath := args[0]
 	p := compile(fpath, true, false, false)
 	if p == nil {
-		return
+		exit(exitIO)
 	}
 	if printlogs(p) {
-		os.Exit(0)
+		exit(exitSource)
+	}
+	// Cpp code is removed after compilation in compile mode.
+	if x.Set.Mode == xset.ModeCompile {
+		mapCompilerLogs()
 	}
 	cxx := p.Cpp()
 	appendStandard(&cxx)
 	path := filepath.Join(x.Set.CppOutDir, x.Set.CppOutName)
-	doSpell(path, cxx)
+	exit(doSpell(path, cxx))
 }
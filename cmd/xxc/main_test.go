@@ -0,0 +1,680 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/the-xlang/xxc/internal/testutil"
+	"github.com/the-xlang/xxc/parser"
+	"github.com/the-xlang/xxc/pkg/x"
+	"github.com/the-xlang/xxc/pkg/xio"
+	"github.com/the-xlang/xxc/pkg/xlog"
+	"github.com/the-xlang/xxc/pkg/xset"
+)
+
+func TestMain(m *testing.M) { testutil.Main(m) }
+
+// Every diagnostic has a code, so it is explainable.
+func TestExplainEveryKey(t *testing.T) {
+	keys := map[string]map[string]string{}
+	for key := range x.Errors {
+		keys[key] = x.ErrorCodes
+	}
+	for key := range x.Warnings {
+		keys[key] = x.WarningCodes
+	}
+	for key, codes := range keys {
+		code, ok := codes[key]
+		if !ok {
+			t.Errorf("%s: key has not code", key)
+			continue
+		}
+		codeKey, msg, ok := codeKey(code)
+		if !ok || codeKey != key {
+			t.Errorf("%s: code %s is not of key", key, code)
+			continue
+		}
+		text := explanation(defaultExplanationLang, code, key, msg)
+		if !strings.HasPrefix(text, "# "+code+": ") || strings.Contains(text, "%") {
+			t.Errorf("%s: invalid explanation:\n%s", code, text)
+		}
+	}
+}
+
+// Example code blocks of explanations.
+var exampleBlock = regexp.MustCompile("(?s)## (Wrong|Fixed)\n\n```x\n(.*?)```")
+
+// Path comments of files in examples such as "// lib/lib.xx".
+var exampleFile = regexp.MustCompile(`(?m)^// (\S+\.xx)\n`)
+
+// compileExample compiles example code in a temporary project and
+// returns codes of errors. Example code is the main.xx file unless
+// it has path comments of files. Then main.xx is compiled if exist,
+// otherwise the first file is compiled as package.
+func compileExample(t *testing.T, code string) []string {
+	dir := t.TempDir()
+	files := map[string]string{"main.xx": code}
+	main := "main.xx"
+	if locs := exampleFile.FindAllStringSubmatchIndex(code, -1); locs != nil {
+		files = map[string]string{}
+		for i, loc := range locs {
+			end := len(code)
+			if i+1 < len(locs) {
+				end = locs[i+1][0]
+			}
+			files[code[loc[2]:loc[3]]] = code[loc[1]:end]
+		}
+		if _, ok := files[main]; !ok {
+			main = code[locs[0][2]:locs[0][3]]
+		}
+	}
+	for name, text := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer func(path string) { x.ProjectPath = path }(x.ProjectPath)
+	x.ProjectPath = dir
+	parser.Reset()
+	f, err := xio.Openfx(filepath.Join(dir, main))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := parser.New(f)
+	p.Parsef(main == "main.xx", false)
+	var codes []string
+	for _, log := range p.Errors {
+		codes = append(codes, log.Code())
+	}
+	return codes
+}
+
+func TestExplanationExamples(t *testing.T) {
+	dir := filepath.Join(x.LangsPath, defaultExplanationLang, localizationExplanations)
+	infos, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, info := range infos {
+		code := strings.TrimSuffix(info.Name(), ".md")
+		t.Run(code, func(t *testing.T) {
+			if _, _, ok := codeKey(code); !ok {
+				t.Fatalf("explanation of unknown code")
+			}
+			bytes, err := os.ReadFile(filepath.Join(dir, info.Name()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			blocks := exampleBlock.FindAllStringSubmatch(string(bytes), -1)
+			if len(blocks) != 2 {
+				t.Fatalf("want wrong and fixed examples, got %d examples", len(blocks))
+			}
+			for _, block := range blocks {
+				codes := compileExample(t, block[2])
+				reported := false
+				for _, c := range codes {
+					reported = reported || c == code
+				}
+				switch block[1] {
+				case "Wrong":
+					if !reported {
+						t.Errorf("wrong example does not report %s: %v", code, codes)
+					}
+				case "Fixed":
+					if len(codes) > 0 {
+						t.Errorf("fixed example has errors: %v", codes)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWriteDiagnostics(t *testing.T) {
+	format, output := diagnosticsFormat, diagnosticsOutput
+	t.Cleanup(func() { diagnosticsFormat, diagnosticsOutput = format, output })
+	diagnosticsFormat = diagnosticsJson
+	diagnosticsOutput = filepath.Join(t.TempDir(), "diagnostics.json")
+	warning := xlog.CompilerLog{Type: xlog.FlatWarning, Key: "doc_ignored", Message: "documentation is ignored"}
+	errLog := xlog.CompilerLog{Type: xlog.FlatError, Key: "no_stdlib", Message: "standard library is not found"}
+	cases := []struct {
+		name string
+		// Logs of each phase.
+		phases [][]xlog.CompilerLog
+		want   []string
+	}{
+		{"no logs", [][]xlog.CompilerLog{nil, nil}, []string{}},
+		{"one phase", [][]xlog.CompilerLog{{warning}}, []string{"doc_ignored"}},
+		{"many phases", [][]xlog.CompilerLog{{warning}, nil, {errLog}}, []string{"doc_ignored", "no_stdlib"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			for _, logs := range c.phases {
+				printcompilerlogs(logs)
+			}
+			if !writeDiagnostics() {
+				t.Fatal("diagnostics are not written")
+			}
+			bytes, err := os.ReadFile(diagnosticsOutput)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Output is one document.
+			var logs []struct{ Key string }
+			if err := json.Unmarshal(bytes, &logs); err != nil {
+				t.Fatalf("invalid document: %v\n%s", err, bytes)
+			}
+			keys := []string{}
+			for _, log := range logs {
+				keys = append(keys, log.Key)
+			}
+			if !reflect.DeepEqual(keys, c.want) {
+				t.Errorf("got %v, want %v", keys, c.want)
+			}
+		})
+	}
+}
+
+// installXXC builds xxc to temporary directory with standard library,
+// API and localizations of repository, like an installation of xxc.
+func installXXC(t *testing.T) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("xxc is not built in short mode")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool is not found")
+	}
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "xxc")
+	if out, err := exec.Command(goTool, "build", "-o", bin, ".").CombinedOutput(); err != nil {
+		t.Fatalf("building xxc: %v\n%s", err, out)
+	}
+	root := testutil.Root()
+	for _, name := range []string{x.Stdlib, "api", x.Localizations} {
+		if err := os.Symlink(filepath.Join(root, name), filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return bin
+}
+
+// requireCompiler skips test if C++ compiler of default settings is not found.
+func requireCompiler(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath(xset.Default.Compiler); err != nil {
+		t.Skip("C++ compiler is not found: " + xset.Default.Compiler)
+	}
+}
+
+// writeSettings writes default X settings changed by edit to directory.
+func writeSettings(t *testing.T, dir string, edit func(*xset.XSet)) {
+	t.Helper()
+	set := *xset.Default
+	if edit != nil {
+		edit(&set)
+	}
+	bytes, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.WriteFiles(t, dir, map[string]string{x.SettingsFile: string(bytes)})
+}
+
+// runXXC runs xxc in directory with input and
+// returns outputs and exit code of it.
+func runXXC(t *testing.T, bin, dir, stdin string, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+	cmd := exec.Command(bin, args...)
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(stdin)
+	var out, errOut strings.Builder
+	cmd.Stdout = &out
+	cmd.Stderr = &errOut
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		code = exitErr.ExitCode()
+	case err != nil:
+		t.Fatal(err)
+	}
+	return out.String(), errOut.String(), code
+}
+
+const helloSource = `main() {
+	outln("hello")
+}
+`
+
+func TestCompileMode(t *testing.T) {
+	requireCompiler(t)
+	bin := installXXC(t)
+	cases := []struct {
+		name string
+		edit func(*xset.XSet)
+		code int
+		// Output of compiler must contain it if not empty.
+		stderr string
+	}{
+		{name: "success", code: exitSuccess},
+		{
+			name: "compiler failed",
+			edit: func(set *xset.XSet) {
+				set.CompilerFlags = []string{"-include", "xxc_missing_header.hpp"}
+			},
+			code:   exitToolchain,
+			stderr: "xxc_missing_header.hpp",
+		},
+		{
+			name:   "compiler not found",
+			edit:   func(set *xset.XSet) { set.Compiler = "xxc-missing-compiler" },
+			code:   exitToolchain,
+			stderr: "xxc-missing-compiler",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			testutil.WriteFiles(t, dir, map[string]string{"main.xx": helloSource})
+			writeSettings(t, dir, func(set *xset.XSet) {
+				set.Mode = xset.ModeCompile
+				if c.edit != nil {
+					c.edit(set)
+				}
+			})
+			_, stderr, code := runXXC(t, bin, dir, "", "main.xx")
+			if code != c.code {
+				t.Fatalf("exit code is %d, want %d\n%s", code, c.code, stderr)
+			}
+			if !strings.Contains(stderr, c.stderr) {
+				t.Errorf("missing %q in output:\n%s", c.stderr, stderr)
+			}
+			out := filepath.Join(dir, xset.Default.CppOutDir)
+			// Generated code is removed after compilation.
+			if _, err := os.Stat(filepath.Join(out, xset.Default.CppOutName)); err == nil {
+				t.Errorf("generated C++ code is not removed")
+			}
+			if c.code != exitSuccess {
+				return
+			}
+			program, err := exec.Command(filepath.Join(out, xset.Default.OutName)).Output()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(program) != "hello\n" {
+				t.Errorf("program printed %q, want %q", program, "hello\n")
+			}
+		})
+	}
+}
+
+const echoSource = `use std::io
+use std::os
+
+main() {
+	line: = std::io::readln()
+	outln("got " + line)
+	std::os::exit(3)
+}
+`
+
+func TestRun(t *testing.T) {
+	requireCompiler(t)
+	bin := installXXC(t)
+	cases := []struct {
+		name   string
+		source string
+		args   []string
+		stdin  string
+		stdout string
+		code   int
+	}{
+		{
+			name:   "input and exit code",
+			source: echoSource,
+			args:   []string{"run", "project/main.xx", "--", "a", "b"},
+			stdin:  "abc\n",
+			stdout: "got abc\n",
+			code:   3,
+		},
+		{
+			name:   "arguments without separator",
+			source: helloSource,
+			args:   []string{"run", "project/main.xx", "a"},
+			code:   exitUsage,
+		},
+		{
+			name:   "missing path",
+			source: helloSource,
+			args:   []string{"run"},
+			code:   exitUsage,
+		},
+		{
+			name:   "source errors",
+			source: "main() {\n\tundefined()\n}\n",
+			args:   []string{"run", "project/main.xx"},
+			code:   exitSource,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// Runs out of project directory.
+			dir := t.TempDir()
+			project := filepath.Join(dir, "project")
+			testutil.WriteFiles(t, project, map[string]string{"main.xx": c.source})
+			writeSettings(t, project, nil)
+			args := append([]string{c.args[0], "-set", filepath.Join(project, x.SettingsFile)}, c.args[1:]...)
+			stdout, stderr, code := runXXC(t, bin, dir, c.stdin, args...)
+			if code != c.code {
+				t.Fatalf("exit code is %d, want %d\n%s", code, c.code, stderr)
+			}
+			if stdout != c.stdout {
+				t.Errorf("stdout is %q, want %q", stdout, c.stdout)
+			}
+			// Nothing is written to project or working directory.
+			for _, path := range []string{filepath.Join(project, xset.Default.CppOutDir), filepath.Join(dir, xset.Default.CppOutDir)} {
+				if _, err := os.Stat(path); err == nil {
+					t.Errorf("output directory is created: %s", path)
+				}
+			}
+		})
+	}
+}
+
+const doubleTestSource = `use std::errors
+
+@test
+double_two() {
+	if double(2) != 4 {
+		panic(std::errors::new("double is wrong"))
+	}
+}
+`
+
+const failingTestSource = `use std::errors
+
+@test
+failing() {
+	panic(std::errors::new("always fails"))
+}
+
+@test
+after_failure() {}
+`
+
+func TestTestCommand(t *testing.T) {
+	requireCompiler(t)
+	bin := installXXC(t)
+	cases := []struct {
+		name  string
+		files map[string]string
+		code  int
+		// Output must contain them.
+		want []string
+	}{
+		{
+			name:  "passing",
+			files: map[string]string{"double_test.xx": doubleTestSource},
+			code:  exitSuccess,
+			want:  []string{"--- PASS: double_two", "PASS\n", "1 passed, 0 failed"},
+		},
+		{
+			// Tests are isolated, failing test is not stops others.
+			name:  "failing",
+			files: map[string]string{"double_test.xx": doubleTestSource, "failing_test.xx": failingTestSource},
+			code:  exitFailure,
+			want: []string{
+				"--- PASS: double_two",
+				"--- FAIL: failing",
+				"    panic: always fails",
+				"--- PASS: after_failure",
+				"FAIL\n",
+				"2 passed, 1 failed",
+			},
+		},
+		{
+			name: "no test files",
+			code: exitSuccess,
+			want: []string{"No test files found"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			files := map[string]string{"lib.xx": "pub double(n int) int { ret n * 2 }\n"}
+			for name, text := range c.files {
+				files[name] = text
+			}
+			testutil.WriteFiles(t, dir, files)
+			writeSettings(t, dir, nil)
+			stdout, stderr, code := runXXC(t, bin, dir, "", "test")
+			if code != c.code {
+				t.Fatalf("exit code is %d, want %d\n%s%s", code, c.code, stdout, stderr)
+			}
+			for _, want := range c.want {
+				if !strings.Contains(stdout+stderr, want) {
+					t.Errorf("missing %q in output:\n%s%s", want, stdout, stderr)
+				}
+			}
+		})
+	}
+}
+
+func TestSetFlags(t *testing.T) {
+	defer func(set *xset.XSet) { x.Set = set }(x.Set)
+	cases := []struct {
+		name string
+		args []string
+		// Parse error must contain err if not empty.
+		err  string
+		want func(*xset.XSet)
+	}{
+		{
+			name: "strings",
+			args: []string{"-out-dir", "out dir", "-out-name", "my program", "-mode", "compile"},
+			want: func(set *xset.XSet) {
+				set.CppOutDir, set.OutName, set.Mode = "out dir", "my program", "compile"
+			},
+		},
+		{
+			name: "bool and count",
+			args: []string{"-timestamp=false", "-max-errors", "3"},
+			want: func(set *xset.XSet) { set.Timestamp, set.MaxErrors = false, 3 },
+		},
+		{
+			name: "list",
+			args: []string{"-disabled-warnings", " doc_ignored, ,exist_undefined_doc"},
+			want: func(set *xset.XSet) { set.DisabledWarnings = []string{"doc_ignored", "exist_undefined_doc"} },
+		},
+		{name: "invalid bool", args: []string{"-timestamp=maybe"}, err: "invalid boolean: maybe"},
+		{name: "negative count", args: []string{"-max-errors", "-1"}, err: "invalid count: -1"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Cleanup(func() { setOverrides = map[string]string{} })
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			setFlags(fs)
+			err := fs.Parse(append(c.args, "main.xx"))
+			switch {
+			case c.err != "":
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("want error %q, got %v", c.err, err)
+				}
+				return
+			case err != nil:
+				t.Fatal(err)
+			}
+			if args := fs.Args(); !reflect.DeepEqual(args, []string{"main.xx"}) {
+				t.Errorf("arguments are %q", args)
+			}
+			set := *xset.Default
+			x.Set = &set
+			overrideXSet()
+			want := *xset.Default
+			c.want(&want)
+			if !reflect.DeepEqual(set, want) {
+				t.Errorf("got %+v, want %+v", set, want)
+			}
+		})
+	}
+}
+
+func TestArguments(t *testing.T) {
+	bin := installXXC(t)
+	dir := t.TempDir()
+	testutil.WriteFiles(t, dir, map[string]string{"src dir/main.xx": helloSource})
+	writeSettings(t, filepath.Join(dir, "settings dir"), nil)
+	set := filepath.Join("settings dir", x.SettingsFile)
+	cases := []struct {
+		name string
+		args []string
+		code int
+		// Output must contain it.
+		out string
+		// File must exist after run if not empty.
+		file string
+	}{
+		{
+			name: "paths with spaces and overrides",
+			args: []string{"-set", set, "-out-dir", "out dir", "-cpp-out-name", "my code.cpp", "src dir/main.xx"},
+			code: exitSuccess,
+			file: filepath.Join("out dir", "my code.cpp"),
+		},
+		{
+			name: "help of command",
+			args: []string{"run", "-h"},
+			code: exitSuccess,
+			out:  "Usage: xxc run [flags] <path> [-- arguments...]",
+		},
+		{
+			name: "unknown flag",
+			args: []string{"fmt", "-unknown"},
+			code: exitUsage,
+			out:  "flag provided but not defined: -unknown",
+		},
+		{
+			name: "many paths",
+			args: []string{"-set", set, "src dir/main.xx", "src dir/main.xx"},
+			code: exitUsage,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stdout, stderr, code := runXXC(t, bin, dir, "", c.args...)
+			if code != c.code {
+				t.Fatalf("exit code is %d, want %d\n%s%s", code, c.code, stdout, stderr)
+			}
+			if !strings.Contains(stdout+stderr, c.out) {
+				t.Errorf("missing %q in output:\n%s%s", c.out, stdout, stderr)
+			}
+			if c.file == "" {
+				return
+			}
+			if _, err := os.Stat(filepath.Join(dir, c.file)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	bin := installXXC(t)
+	cases := []struct {
+		name  string
+		files map[string]string
+		// Settings are not written if nil.
+		edit func(*xset.XSet)
+		args []string
+		code int
+		// Output must contain it.
+		out string
+	}{
+		{
+			name:  "success",
+			files: map[string]string{"main.xx": helloSource},
+			edit:  func(*xset.XSet) {},
+			args:  []string{"main.xx"},
+			code:  exitSuccess,
+		},
+		{
+			name: "help",
+			args: []string{"help"},
+			code: exitSuccess,
+			out:  "Exit codes:\n0",
+		},
+		{
+			name: "no arguments",
+			code: exitUsage,
+			out:  "Usage: xxc [flags] <path>",
+		},
+		{
+			name:  "missing settings",
+			files: map[string]string{"main.xx": helloSource},
+			args:  []string{"main.xx"},
+			code:  exitSettings,
+			out:   "X0131",
+		},
+		{
+			name:  "invalid settings",
+			files: map[string]string{"main.xx": helloSource, x.SettingsFile: "{bad"},
+			args:  []string{"main.xx"},
+			code:  exitSettings,
+			out:   "X0132",
+		},
+		{
+			name:  "source errors",
+			files: map[string]string{"main.xx": "main() {\n\tmissing\n}\n"},
+			edit:  func(*xset.XSet) {},
+			args:  []string{"main.xx"},
+			code:  exitSource,
+			out:   "X0024",
+		},
+		{
+			name: "missing source",
+			edit: func(*xset.XSet) {},
+			args: []string{"missing.xx"},
+			code: exitIO,
+			out:  "missing.xx",
+		},
+		{
+			name:  "write failure",
+			files: map[string]string{"main.xx": helloSource, "blocker": ""},
+			edit:  func(set *xset.XSet) { set.CppOutDir = "blocker" },
+			args:  []string{"main.xx"},
+			code:  exitIO,
+			out:   "blocker",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			testutil.WriteFiles(t, dir, c.files)
+			if c.edit != nil {
+				writeSettings(t, dir, c.edit)
+			}
+			stdout, stderr, code := runXXC(t, bin, dir, "", c.args...)
+			if code != c.code {
+				t.Fatalf("exit code is %d, want %d\n%s%s", code, c.code, stdout, stderr)
+			}
+			if !strings.Contains(stdout+stderr, c.out) {
+				t.Errorf("missing %q in output:\n%s%s", c.out, stdout, stderr)
+			}
+		})
+	}
+}
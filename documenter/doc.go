@@ -2,9 +2,12 @@ package documenter
 
 import (
 	"encoding/json"
+	"strings"
 
 	"github.com/the-xlang/xxc/ast/models"
+	"github.com/the-xlang/xxc/lex/tokens"
 	"github.com/the-xlang/xxc/parser"
+	"github.com/the-xlang/xxc/pkg/x"
 	"github.com/the-xlang/xxc/pkg/xtype"
 )
 
@@ -16,6 +19,7 @@ type generic struct {
 
 type use struct {
 	Path   string `json:"path"`
+	Alias  string `json:"alias"`
 	Stdlib bool   `json:"stdlib"`
 }
 
@@ -80,7 +84,8 @@ func uses(p *parser.Parser) []use {
 	for i, u := range p.Uses {
 		uses[i] = use{
 			Path:   u.LinkString,
-			Stdlib: u.LinkString[0] != '"',
+			Alias:  u.Alias,
+			Stdlib: strings.HasPrefix(u.LinkString, x.Stdlib+tokens.DOUBLE_COLON),
 		}
 	}
 	return uses
@@ -0,0 +1,199 @@
+package dumper
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/the-xlang/xxc/ast"
+	"github.com/the-xlang/xxc/ast/models"
+	"github.com/the-xlang/xxc/lex"
+)
+
+type Tok = lex.Tok
+
+type pos struct {
+	Row    int `json:"row"`
+	Column int `json:"column"`
+}
+
+type token struct {
+	pos
+	Kind string `json:"kind"`
+	Id   uint8  `json:"id"`
+}
+
+// node is a node of AST.
+// Value is the text of node such as expression, data type or comment.
+type node struct {
+	Kind      string  `json:"kind"`
+	Pos       *pos    `json:"pos,omitempty"`
+	Id        string  `json:"id,omitempty"`
+	Value     string  `json:"value,omitempty"`
+	Pub       bool    `json:"pub,omitempty"`
+	Const     bool    `json:"const,omitempty"`
+	Variadic  bool    `json:"variadic,omitempty"`
+	Reference bool    `json:"reference,omitempty"`
+	Desc      string  `json:"description,omitempty"`
+	Children  []*node `json:"children,omitempty"`
+}
+
+type dump struct {
+	File   string  `json:"file"`
+	Tokens []token `json:"tokens,omitempty"`
+	Tree   []*node `json:"tree"`
+}
+
+func posOf(tok Tok) *pos {
+	if tok.File == nil {
+		return nil
+	}
+	return &pos{Row: tok.Row, Column: tok.Column}
+}
+
+// exprString returns source text of tokens.
+// Tokens are separated by space if they are separated in source.
+func exprString(toks []Tok) string {
+	var expr strings.Builder
+	for i, tok := range toks {
+		if i > 0 {
+			prev := toks[i-1]
+			if tok.Row != prev.Row || tok.Column > prev.Column+prev.Length() {
+				expr.WriteByte(' ')
+			}
+		}
+		expr.WriteString(tok.Kind)
+	}
+	return expr.String()
+}
+
+// newNode returns node with fields of AST node.
+func newNode(n models.Node) *node {
+	t := reflect.TypeOf(n)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	nd := &node{Kind: t.Name(), Pos: posOf(n.Pos())}
+	switch t := reflect.Indirect(reflect.ValueOf(n)).Interface().(type) {
+	case models.Expr:
+		nd.Value = exprString(t.Toks)
+	case models.DataType:
+		// Types of multi-typed data type are children of it.
+		if !t.MultiTyped {
+			nd.Value = t.Kind
+		}
+	case models.Comment:
+		nd.Value = t.Content
+	case models.Attribute:
+		nd.Value = t.Tag.Kind
+	case models.Preprocessor:
+		nd.Value = t.String()
+	case models.Use:
+		nd.Value = t.LinkString
+	case models.Namespace:
+		nd.Id = strings.Join(t.Ids, "::")
+	case models.GenericType:
+		nd.Id = t.Id
+	case models.Func:
+		nd.Id, nd.Pub = t.Id, t.Pub
+	case models.Param:
+		nd.Id = t.Id
+		nd.Variadic, nd.Reference = t.Variadic, t.Reference
+	case models.Var:
+		nd.Id, nd.Pub, nd.Const, nd.Desc = t.Id, t.Pub, t.Const, t.Desc
+	case models.Type:
+		nd.Id, nd.Pub, nd.Desc = t.Id, t.Pub, t.Desc
+	case models.Enum:
+		nd.Id, nd.Pub, nd.Desc = t.Id, t.Pub, t.Desc
+	case models.EnumItem:
+		nd.Id = t.Id
+	case models.Struct:
+		nd.Id, nd.Pub = t.Id, t.Pub
+	case models.Trait:
+		nd.Id, nd.Pub, nd.Desc = t.Id, t.Pub, t.Desc
+	case models.Impl:
+		nd.Value = t.Trait.Kind
+	case models.Assign:
+		nd.Value = t.Setter.Kind
+	case models.Case:
+		// Keyword of case, default case is distinguished by it.
+		nd.Value = t.Tok.Kind
+	case models.Goto:
+		nd.Id = t.Label
+	case models.Label:
+		nd.Id = t.Label
+	}
+	return nd
+}
+
+// builder builds nodes by walking AST.
+type builder struct {
+	// Children of nodes which are in visiting.
+	stack [][]*node
+	// Visiting nodes, nil for nodes which are not dumped.
+	nodes []*node
+}
+
+func (b *builder) Visit(n models.Node) ast.Visitor {
+	if n == nil {
+		nd := b.nodes[len(b.nodes)-1]
+		children := b.stack[len(b.stack)-1]
+		b.nodes = b.nodes[:len(b.nodes)-1]
+		b.stack = b.stack[:len(b.stack)-1]
+		if nd != nil {
+			nd.Children = children
+			children = []*node{nd}
+		}
+		last := len(b.stack) - 1
+		b.stack[last] = append(b.stack[last], children...)
+		return nil
+	}
+	var nd *node
+	switch n.(type) {
+	case models.Object, *models.Object, models.Statement, *models.Statement:
+		// Objects and statements are wrappers,
+		// their data are dumped instead of them.
+	default:
+		nd = newNode(n)
+	}
+	b.nodes = append(b.nodes, nd)
+	b.stack = append(b.stack, nil)
+	return b
+}
+
+func tree(objs []models.Object) []*node {
+	b := &builder{stack: [][]*node{{}}}
+	for _, obj := range objs {
+		ast.Walk(b, obj)
+	}
+	return b.stack[0]
+}
+
+func tokens(toks []Tok) []token {
+	dumped := make([]token, len(toks))
+	for i, tok := range toks {
+		dumped[i] = token{
+			pos:  pos{Row: tok.Row, Column: tok.Column},
+			Kind: tok.Kind,
+			Id:   tok.Id,
+		}
+	}
+	return dumped
+}
+
+// Dump returns JSON of AST tree of file.
+// Tokens are dumped too if toks is not nil.
+func Dump(path string, toks []Tok, objs []models.Object) (string, error) {
+	d := dump{
+		File: path,
+		Tree: tree(objs),
+	}
+	if toks != nil {
+		d.Tokens = tokens(toks)
+	}
+	bytes, err := json.MarshalIndent(d, "", "\t")
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
@@ -0,0 +1,128 @@
+package dumper
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/the-xlang/xxc/ast"
+	"github.com/the-xlang/xxc/internal/testutil"
+	"github.com/the-xlang/xxc/lex"
+	"github.com/the-xlang/xxc/pkg/xio"
+)
+
+func TestMain(m *testing.M) { testutil.Main(m) }
+
+const source = `//doc: count
+@inline
+pub count(n int, ...v int) int {
+	match n {
+	case 1, 2:
+		ret n+len(v)
+	default:
+		ret 0
+	}
+}
+`
+
+// outline writes nodes as lines of kind, identifier, value and position.
+// Children are indented by their depth.
+func outline(sb *strings.Builder, nodes []*node, depth int) {
+	for _, n := range nodes {
+		sb.WriteString(strings.Repeat("  ", depth))
+		sb.WriteString(n.Kind)
+		if n.Id != "" {
+			sb.WriteString(" " + n.Id)
+		}
+		if n.Value != "" {
+			sb.WriteString(fmt.Sprintf(" %q", n.Value))
+		}
+		if n.Pub {
+			sb.WriteString(" pub")
+		}
+		if n.Variadic {
+			sb.WriteString(" variadic")
+		}
+		if n.Pos != nil {
+			sb.WriteString(fmt.Sprintf(" %d:%d", n.Pos.Row, n.Pos.Column))
+		}
+		sb.WriteByte('\n')
+		outline(sb, n.Children, depth+1)
+	}
+}
+
+func dumpSource(t *testing.T, withToks bool) (dump, []Tok) {
+	t.Helper()
+	l := lex.NewLex(&xio.File{Dir: "/p", Name: "main.xx", Data: []rune(source)})
+	toks := l.Lex()
+	b := ast.NewBuilder(toks)
+	b.Build()
+	if len(l.Logs) > 0 || len(b.Errors) > 0 {
+		t.Fatalf("unexpected errors: %+v %+v", l.Logs, b.Errors)
+	}
+	dumped := toks
+	if !withToks {
+		dumped = nil
+	}
+	text, err := Dump("/p/main.xx", dumped, b.Tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d dump
+	if err := json.Unmarshal([]byte(text), &d); err != nil {
+		t.Fatal(err)
+	}
+	return d, toks
+}
+
+func TestDumpTree(t *testing.T) {
+	d, _ := dumpSource(t, false)
+	if d.File != "/p/main.xx" || d.Tokens != nil {
+		t.Errorf("file is %q, tokens are %v", d.File, d.Tokens)
+	}
+	var got strings.Builder
+	outline(&got, d.Tree, 0)
+	want := `Comment "doc: count" 1:1
+Attribute "inline" 2:1
+Func count pub 3:5
+  Param n 3:11
+    DataType "int" 3:13
+  Param v variadic 3:21
+    DataType "int" 3:23
+  RetType 3:28
+    DataType "int" 3:28
+  Block 4:5
+    Match 4:5
+      Expr "n" 4:11
+      Case "case" 5:5
+        Expr "1" 5:10
+        Expr "2" 5:13
+        Block 6:9
+          Ret 6:9
+            Expr "n+len(v)" 6:13
+      Case "default" 7:5
+        Block 8:9
+          Ret 8:9
+            Expr "0" 8:13
+`
+	if got.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got.String(), want)
+	}
+}
+
+func TestDumpTokens(t *testing.T) {
+	d, toks := dumpSource(t, true)
+	if len(d.Tokens) != len(toks) {
+		t.Fatalf("got %d tokens, want %d", len(d.Tokens), len(toks))
+	}
+	for i, tok := range toks {
+		want := token{pos: pos{Row: tok.Row, Column: tok.Column}, Kind: tok.Kind, Id: tok.Id}
+		if d.Tokens[i] != want {
+			t.Errorf("token %d is %+v, want %+v", i, d.Tokens[i], want)
+		}
+	}
+	if attr := d.Tokens[1]; attr.Kind != "@" || attr.Row != 2 || attr.Column != 1 {
+		t.Errorf("token of attribute is %+v", attr)
+	}
+}
@@ -0,0 +1,105 @@
+package formatter
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/the-xlang/xxc/lex/tokens"
+)
+
+// declColon returns index of colon if tokens are
+// constant declaration with initializer, -1 if not.
+func declColon(toks []Tok) int {
+	i := 0
+	if i < len(toks) && toks[i].Id == tokens.Pub {
+		i++
+	}
+	if i >= len(toks) || toks[i].Id != tokens.Const {
+		return -1
+	}
+	i++
+	if i+1 >= len(toks) || toks[i].Id != tokens.Id || toks[i+1].Id != tokens.Colon {
+		return -1
+	}
+	for _, tok := range toks[i+2:] {
+		if tok.Id == tokens.Operator && tok.Kind == tokens.EQUAL {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// width returns display width of text.
+func width(text string) int { return utf8.RuneCountInString(text) }
+
+// alignable reports lines are in same alignment section or not.
+func alignable(prev, ln outline) bool {
+	return !ln.blank && ln.level == prev.level
+}
+
+// alignGroups calls f for each group of consecutive alignable lines
+// which are has offset. Offset function returns -1 if line has not.
+func (ft *formatter) alignGroups(offset func(outline) int, f func([]outline)) {
+	for i := 0; i < len(ft.lines); i++ {
+		if offset(ft.lines[i]) == -1 {
+			continue
+		}
+		j := i + 1
+		for j < len(ft.lines) && offset(ft.lines[j]) != -1 && alignable(ft.lines[j-1], ft.lines[j]) {
+			j++
+		}
+		if j-i > 1 {
+			f(ft.lines[i:j])
+		}
+		i = j - 1
+	}
+}
+
+// pad inserts spaces to offset of line for align offset to column.
+// Returns count of inserted spaces.
+func pad(ln *outline, offset, column int) int {
+	n := column - width(ln.text[:offset])
+	if n <= 0 {
+		return 0
+	}
+	ln.text = ln.text[:offset] + strings.Repeat(" ", n) + ln.text[offset:]
+	return n
+}
+
+// align aligns colons of consecutive constant declarations
+// and consecutive trailing comments.
+func (ft *formatter) align() {
+	colon := func(ln outline) int { return ln.colon }
+	ft.alignGroups(colon, func(lns []outline) {
+		column := 0
+		for _, ln := range lns {
+			if w := width(ln.text[:ln.colon]); w > column {
+				column = w
+			}
+		}
+		for i := range lns {
+			n := pad(&lns[i], lns[i].colon, column)
+			if lns[i].trailing != -1 {
+				lns[i].trailing += n
+			}
+		}
+	})
+	trailing := func(ln outline) int {
+		// Multiline tokens are breaks alignment.
+		if ln.trailing == -1 || strings.Contains(ln.text[:ln.trailing], "\n") {
+			return -1
+		}
+		return ln.trailing
+	}
+	ft.alignGroups(trailing, func(lns []outline) {
+		column := 0
+		for _, ln := range lns {
+			if w := width(ln.text[:ln.trailing]); w > column {
+				column = w
+			}
+		}
+		for i := range lns {
+			pad(&lns[i], lns[i].trailing, column)
+		}
+	})
+}
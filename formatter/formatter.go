@@ -0,0 +1,442 @@
+package formatter
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/the-xlang/xxc/ast"
+	"github.com/the-xlang/xxc/ast/models"
+	"github.com/the-xlang/xxc/lex"
+	"github.com/the-xlang/xxc/lex/tokens"
+	"github.com/the-xlang/xxc/pkg/x"
+	"github.com/the-xlang/xxc/pkg/xio"
+	"github.com/the-xlang/xxc/pkg/xlog"
+)
+
+type Tok = lex.Tok
+
+// line is a source line.
+type line struct {
+	toks []Tok
+	// Line has blank line(s) before.
+	blank bool
+}
+
+// outline is a formatted line.
+type outline struct {
+	text  string
+	blank bool
+	// Line is a use declaration which is sortable.
+	use bool
+	// Line ends with opener brace.
+	opens bool
+	// Line starts with closer brace.
+	closes bool
+	// Line ends with comment.
+	comment bool
+	// Indentation level of line.
+	level int
+	// Offset of colon of declaration, -1 if line is not declaration.
+	colon int
+	// Offset of trailing comment with leading space, -1 if not exist.
+	trailing int
+	// First token of line.
+	head Tok
+	// Last token of line.
+	last Tok
+}
+
+// opener is an open brace.
+type opener struct {
+	indent bool // Opener indents inner lines.
+	decl   bool // Body of struct, trait, impl or enum.
+	match  bool // Body of match statement.
+}
+
+type formatter struct {
+	indent   string
+	eol      string
+	useRows  map[int]bool
+	declRows map[int]bool
+	// Rows of headers and parameters of functions.
+	fnRows map[int]bool
+	stack  []opener
+	lines  []outline
+	// Start of openers of current line in stack.
+	base int
+}
+
+// tree returns AST tree of file.
+// Returns errors if file has syntax errors.
+func tree(f *xio.File) ([]Tok, []models.Object, []xlog.CompilerLog) {
+	l := lex.NewLex(f)
+	toks := l.Lex()
+	if len(l.Logs) > 0 {
+		return nil, nil, l.Logs
+	}
+	b := ast.NewBuilder(toks)
+	b.Build()
+	if len(b.Errors) > 0 {
+		return nil, nil, b.Errors
+	}
+	return toks, b.Tree, nil
+}
+
+// Format returns canonical formatted source code of file.
+// Indent is the indentation of one level.
+// Returns errors if file has syntax errors.
+func Format(f *xio.File, indent string) (string, []xlog.CompilerLog) {
+	toks, objs, errors := tree(f)
+	if len(errors) > 0 {
+		return "", errors
+	}
+	ft := formatter{
+		indent:   indent,
+		eol:      "\n",
+		useRows:  map[int]bool{},
+		declRows: map[int]bool{},
+		fnRows:   map[int]bool{},
+	}
+	for _, obj := range objs {
+		switch obj.Data.(type) {
+		case models.Use:
+			ft.useRows[obj.Tok.Row] = true
+		case models.Struct, models.Trait, models.Impl, models.Enum:
+			ft.declRows[obj.Tok.Row] = true
+		}
+		ast.Inspect(obj, func(node models.Node) bool {
+			switch node.(type) {
+			case *models.Func, models.Func, *models.Param, models.Param, *models.RetType, models.RetType:
+				ft.fnRows[node.Pos().Row] = true
+			}
+			return true
+		})
+	}
+	// Line endings of file are kept.
+	if strings.Contains(string(f.Data), "\r\n") {
+		ft.eol = "\r\n"
+	}
+	l := lex.NewLex(f)
+	l.KeepComments = true
+	for _, ln := range lines(l.Lex()) {
+		ft.line(ln)
+	}
+	ft.sortUses()
+	ft.align()
+	src := ft.String()
+	// Formatting changes only layout and order of uses, so tokens should be same.
+	// Otherwise formatter has a bug and result is not safe to use.
+	formatted := &xio.File{Dir: f.Dir, Name: f.Name, Data: []rune(src)}
+	ftoks, fobjs, errors := tree(formatted)
+	if len(errors) > 0 || !sameToks(toks, objs, ftoks, fobjs) {
+		return "", []xlog.CompilerLog{{
+			Type:    xlog.FlatError,
+			Message: x.GetError("format_failed", f.Path()),
+			Key:     "format_failed",
+			Args:    []any{f.Path()},
+		}}
+	}
+	return src, nil
+}
+
+// canonical returns tokens without use declarations
+// and sorted texts of use declarations.
+func canonical(toks []Tok, objs []models.Object) ([]Tok, []string) {
+	rows := map[int]bool{}
+	for _, obj := range objs {
+		if _, ok := obj.Data.(models.Use); ok {
+			rows[obj.Tok.Row] = true
+		}
+	}
+	var others []Tok
+	var uses []string
+	for i := 0; i < len(toks); i++ {
+		if !rows[toks[i].Row] || toks[i].Id == tokens.Comment {
+			others = append(others, toks[i])
+			continue
+		}
+		var use strings.Builder
+		row := toks[i].Row
+		for ; i < len(toks) && toks[i].Row == row; i++ {
+			use.WriteString(toks[i].Kind)
+			use.WriteByte(' ')
+		}
+		uses = append(uses, use.String())
+		i--
+	}
+	sort.Strings(uses)
+	return others, uses
+}
+
+func sameToks(a []Tok, aobjs []models.Object, b []Tok, bobjs []models.Object) bool {
+	a, auses := canonical(a, aobjs)
+	b, buses := canonical(b, bobjs)
+	if len(a) != len(b) || strings.Join(auses, "\n") != strings.Join(buses, "\n") {
+		return false
+	}
+	for i := range a {
+		if a[i].Id != b[i].Id || text(a[i]) != text(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// lines returns tokens grouped by lines.
+func lines(toks []Tok) []line {
+	var lns []line
+	end := 0 // Row of end of last token.
+	for _, tok := range toks {
+		if len(lns) == 0 || tok.Row > end {
+			lns = append(lns, line{blank: len(lns) > 0 && tok.Row > end+1})
+		}
+		ln := &lns[len(lns)-1]
+		ln.toks = append(ln.toks, tok)
+		end = tok.Row + strings.Count(tok.Kind, "\n")
+	}
+	return lns
+}
+
+// text returns formatted text of token.
+func text(tok Tok) string {
+	if tok.Id == tokens.Comment {
+		return strings.TrimRightFunc(tok.Kind, isSpace)
+	}
+	return tok.Kind
+}
+
+func isSpace(r rune) bool { return r == ' ' || r == '\t' || r == '\r' || r == '\n' }
+
+func isOpener(tok Tok) bool {
+	if tok.Id != tokens.Brace {
+		return false
+	}
+	switch tok.Kind {
+	case tokens.LBRACE, tokens.LPARENTHESES, tokens.LBRACKET:
+		return true
+	}
+	return false
+}
+
+func isCloser(tok Tok) bool {
+	if tok.Id != tokens.Brace {
+		return false
+	}
+	switch tok.Kind {
+	case tokens.RBRACE, tokens.RPARENTHESES, tokens.RBRACKET:
+		return true
+	}
+	return false
+}
+
+func (ft *formatter) level() int {
+	n := 0
+	for _, o := range ft.stack {
+		if o.indent {
+			n++
+		}
+	}
+	return n
+}
+
+func (ft *formatter) top() *opener {
+	if len(ft.stack) == 0 {
+		return nil
+	}
+	return &ft.stack[len(ft.stack)-1]
+}
+
+func (ft *formatter) pop() {
+	if len(ft.stack) == 0 {
+		return
+	}
+	ft.stack = ft.stack[:len(ft.stack)-1]
+	if ft.base > len(ft.stack) {
+		ft.base = len(ft.stack)
+	}
+}
+
+// joinable reports line is joinable to last line or not.
+// Opener brace lines of blocks and else lines are joined.
+func (ft *formatter) joinable(ln line) bool {
+	if ln.blank || len(ft.lines) == 0 {
+		return false
+	}
+	last := ft.lines[len(ft.lines)-1]
+	if last.comment || last.head.Id == tokens.Comment {
+		return false
+	}
+	tok := ln.toks[0]
+	switch {
+	case tok.Id == tokens.Else:
+		return last.last.Id == tokens.Brace && last.last.Kind == tokens.RBRACE
+	case tok.Id == tokens.Brace && tok.Kind == tokens.LBRACE:
+		if last.opens || last.last.Id == tokens.Comma {
+			return false
+		}
+		top := ft.top()
+		if top == nil || top.decl {
+			return true
+		}
+		switch last.head.Id {
+		case tokens.If, tokens.Else, tokens.For, tokens.Match:
+			return true
+		}
+	}
+	return false
+}
+
+// head returns first token of line which is not closer brace.
+func head(toks []Tok) Tok {
+	for _, tok := range toks {
+		if !isCloser(tok) {
+			return tok
+		}
+	}
+	return toks[0]
+}
+
+func (ft *formatter) line(ln line) {
+	if ft.joinable(ln) {
+		out := &ft.lines[len(ft.lines)-1]
+		var sb strings.Builder
+		sb.WriteString(out.text)
+		sb.WriteByte(' ')
+		offsets := ft.write(&sb, out.head, ln.toks, 0)
+		out.text = sb.String()
+		out.opens = isOpener(ln.toks[len(ln.toks)-1])
+		out.last = ln.toks[len(ln.toks)-1]
+		out.comment = out.last.Id == tokens.Comment
+		out.use = false
+		out.trailing = -1
+		if out.comment {
+			out.trailing = offsets[len(offsets)-1]
+		}
+		return
+	}
+	// Leading closer braces dedent the line.
+	closers := 0
+	for closers < len(ln.toks) && isCloser(ln.toks[closers]) {
+		ft.pop()
+		closers++
+	}
+	level := ft.level()
+	tok := head(ln.toks)
+	if top := ft.top(); top != nil && top.match {
+		if tok.Id == tokens.Case || tok.Id == tokens.Default {
+			level--
+		}
+	}
+	if isLabel(ln.toks) && level > 0 {
+		level--
+	}
+	ft.base = len(ft.stack)
+	var sb strings.Builder
+	sb.WriteString(strings.Repeat(ft.indent, level))
+	offsets := ft.write(&sb, tok, ln.toks, closers)
+	last := ln.toks[len(ln.toks)-1]
+	colon := -1
+	if i := declColon(ln.toks); i != -1 {
+		colon = offsets[i]
+	}
+	trailing := -1
+	if last.Id == tokens.Comment && len(ln.toks) > 1 {
+		trailing = offsets[len(offsets)-1]
+	}
+	ft.lines = append(ft.lines, outline{
+		text:     sb.String(),
+		blank:    ln.blank,
+		use:      ft.useRows[tok.Row] && !hasComment(ln.toks),
+		opens:    isOpener(last),
+		closes:   isCloser(ln.toks[0]),
+		comment:  last.Id == tokens.Comment,
+		head:     tok,
+		last:     last,
+		level:    level,
+		colon:    colon,
+		trailing: trailing,
+	})
+}
+
+// isLabel reports tokens are label or not.
+// Labels are outdented by one level.
+func isLabel(toks []Tok) bool {
+	if len(toks) > 2 && toks[2].Id == tokens.Comment {
+		toks = toks[:2]
+	}
+	return len(toks) == 2 && toks[0].Id == tokens.Id && toks[1].Id == tokens.Colon
+}
+
+func hasComment(toks []Tok) bool {
+	for _, tok := range toks {
+		if tok.Id == tokens.Comment {
+			return true
+		}
+	}
+	return false
+}
+
+// write writes tokens of line with spacing and tracks braces.
+// Head is the first token of line.
+// Skip is count of leading tokens which are tracked already.
+// Returns offsets of tokens in builder, before of spaces.
+func (ft *formatter) write(sb *strings.Builder, head Tok, toks []Tok, skip int) []int {
+	offsets := make([]int, len(toks))
+	l := newLayout(toks, ft.declRows[head.Row], ft.fnRows[head.Row])
+	for i, tok := range toks {
+		offsets[i] = sb.Len()
+		if i > 0 && l.space(i) {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(text(tok))
+		switch {
+		case i < skip:
+		case isOpener(tok):
+			ft.stack = append(ft.stack, opener{
+				decl:  ft.declRows[head.Row],
+				match: head.Id == tokens.Match,
+			})
+		case isCloser(tok):
+			ft.pop()
+		}
+	}
+	// Only first unclosed opener of line indents.
+	if ft.base < len(ft.stack) {
+		ft.stack[ft.base].indent = true
+	}
+	return offsets
+}
+
+// sortUses sorts contiguous use declarations.
+func (ft *formatter) sortUses() {
+	for i := 0; i < len(ft.lines); i++ {
+		if !ft.lines[i].use {
+			continue
+		}
+		j := i + 1
+		for j < len(ft.lines) && ft.lines[j].use && !ft.lines[j].blank {
+			j++
+		}
+		uses := ft.lines[i:j]
+		blank := uses[0].blank
+		sort.SliceStable(uses, func(a, b int) bool { return uses[a].text < uses[b].text })
+		for k := range uses {
+			uses[k].blank = false
+		}
+		uses[0].blank = blank
+		i = j - 1
+	}
+}
+
+// String returns formatted source code.
+func (ft *formatter) String() string {
+	var sb strings.Builder
+	for i, ln := range ft.lines {
+		if i > 0 && ln.blank && !ft.lines[i-1].opens && !ln.closes {
+			sb.WriteString(ft.eol)
+		}
+		sb.WriteString(ln.text)
+		sb.WriteString(ft.eol)
+	}
+	return sb.String()
+}
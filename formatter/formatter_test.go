@@ -0,0 +1,174 @@
+package formatter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/the-xlang/xxc/pkg/x"
+	"github.com/the-xlang/xxc/pkg/xio"
+)
+
+func format(t *testing.T, src string) string {
+	t.Helper()
+	f := &xio.File{Dir: t.TempDir(), Name: "main.xx", Data: []rune(src)}
+	formatted, logs := Format(f, "\t")
+	if logs != nil {
+		t.Fatalf("format failed: %s", logs[0].Message)
+	}
+	return formatted
+}
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "sorted uses",
+			src:  "use std::math\nuse std::conv\n\nmain() {}\n",
+			want: "use std::conv\nuse std::math\n\nmain() {}\n",
+		},
+		{
+			name: "indentation and spaces",
+			src:  "main(){\nn:=1\nif n>2 {\noutln(n)\n}\n}\n",
+			want: "main() {\n\tn: = 1\n\tif n > 2 {\n\t\toutln(n)\n\t}\n}\n",
+		},
+		{
+			name: "fields",
+			src:  "struct point {\n  x: int\n    y: int\n}\n",
+			want: "struct point {\n\tx: int\n\ty: int\n}\n",
+		},
+		{
+			name: "blank lines",
+			src:  "f() {}\n\n\n\nmain() {\n\n\n\tf()\n}\n",
+			want: "f() {}\n\nmain() {\n\tf()\n}\n",
+		},
+		{
+			name: "trailing comments",
+			src:  "main() {\n\tp: = [1,2] // list\n\toutln(p)   // print\n}",
+			want: "main() {\n\tp: = [1, 2] // list\n\toutln(p)    // print\n}\n",
+		},
+		{
+			name: "match cases",
+			src:  "main() {\nmatch 1 {\ncase 1:\noutln(1)\ndefault:\noutln(0)\n}\n}\n",
+			want: "main() {\n\tmatch 1 {\n\tcase 1:\n\t\toutln(1)\n\tdefault:\n\t\toutln(0)\n\t}\n}\n",
+		},
+		{
+			name: "line endings",
+			src:  "main() {\r\noutln(1)\r\n}\r\n",
+			want: "main() {\r\n\toutln(1)\r\n}\r\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := format(t, c.src)
+			if got != c.want {
+				t.Errorf("got:\n%q\nwant:\n%q", got, c.want)
+			}
+			if again := format(t, got); again != got {
+				t.Errorf("formatting is not idempotent:\n%q\n%q", got, again)
+			}
+		})
+	}
+}
+
+// Sources which are spaced differently are formatted to same layout.
+func TestFormatSpacing(t *testing.T) {
+	cases := []struct {
+		name string
+		// Same code with different spacing.
+		a, b string
+		want string
+	}{
+		{
+			name: "else",
+			a:    "main() {\n\tif true {\n\t}else {\n\t}\n}\n",
+			b:    "main() {\n\tif true {\n\t} else{\n\t}\n}\n",
+			want: "main() {\n\tif true {\n\t} else {\n\t}\n}\n",
+		},
+		{
+			name: "types of declarations",
+			a:    "x:int = 1\n",
+			b:    "x : int = 1\n",
+			want: "x: int = 1\n",
+		},
+		{
+			name: "binary operators",
+			a:    "x: = 1+2*3\n",
+			b:    "x: = 1 +2 * 3\n",
+			want: "x: = 1 + 2 * 3\n",
+		},
+		{
+			name: "unary operators",
+			a:    "main() {\n\tx: = - 1\n\tp: = & x\n\ty: = * p+x\n\tret\n}\n",
+			b:    "main() {\n\tx: = -1\n\tp: = &x\n\ty: = *p + x\n\tret\n}\n",
+			want: "main() {\n\tx: = -1\n\tp: = &x\n\ty: = *p + x\n\tret\n}\n",
+		},
+		{
+			name: "calls and indexes",
+			a:    "main() {\n\ts: = [1,2,3]\n\toutln (s [0]+s[1 :])\n}\n",
+			b:    "main() {\n\ts: = [ 1, 2, 3 ]\n\toutln( s[ 0 ] + s[1: ] )\n}\n",
+			want: "main() {\n\ts: = [1, 2, 3]\n\toutln(s[0] + s[1:])\n}\n",
+		},
+		{
+			name: "types of parameters and results",
+			a:    "f(p *int, s []int, ...v int) *int {\n\tret p\n}\n",
+			b:    "f(p*int, s[]int, ... v int) * int{\n\tret p\n}\n",
+			want: "f(p *int, s []int, ...v int) *int {\n\tret p\n}\n",
+		},
+		{
+			name: "literals and blocks",
+			a:    "main() {\n\tm: = [int:str]{1: \"a\"}\n\tif m[1]==\"a\" {outln(m)}\n}\n",
+			b:    "main() {\n\tm: = [int : str] { 1:\"a\" }\n\tif m[1] == \"a\"{ outln(m) }\n}\n",
+			want: "main() {\n\tm: = [int:str]{1: \"a\"}\n\tif m[1] == \"a\" { outln(m) }\n}\n",
+		},
+		{
+			name: "selectors",
+			a:    "use std::math\n\nmain() {\n\toutln(math :: PI . str)\n}\n",
+			b:    "use std::math\n\nmain() {\n\toutln(math::PI.str)\n}\n",
+			want: "use std::math\n\nmain() {\n\toutln(math::PI.str)\n}\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a, b := format(t, c.a), format(t, c.b)
+			if a != b {
+				t.Errorf("different spellings are formatted differently:\n%q\n%q", a, b)
+			}
+			if a != c.want {
+				t.Errorf("got:\n%q\nwant:\n%q", a, c.want)
+			}
+		})
+	}
+}
+
+// Standard library is a corpus of real code for idempotency.
+func TestFormatIdempotent(t *testing.T) {
+	std := filepath.Join("..", x.Stdlib)
+	err := filepath.Walk(std, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, x.SrcExt) {
+			return err
+		}
+		f, err := xio.Openfx(path)
+		if err != nil {
+			return err
+		}
+		once, logs := Format(f, "\t")
+		if logs != nil {
+			// Declarations of built-in definitions are not valid code.
+			return nil
+		}
+		f.Data = []rune(once)
+		twice, logs := Format(f, "\t")
+		if logs != nil || twice != once {
+			t.Errorf("%s: formatting is not idempotent", path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,356 @@
+package formatter
+
+import "github.com/the-xlang/xxc/lex/tokens"
+
+// Spacing of a side of token.
+const (
+	unset  = iota // Spacing is decided by other side.
+	never         // Never put space.
+	always        // Always put space.
+)
+
+// Operators which are always binary.
+var binaryOps = map[string]bool{
+	tokens.EQUAL:         true,
+	tokens.PLUS_EQUAL:    true,
+	tokens.MINUS_EQUAL:   true,
+	tokens.STAR_EQUAL:    true,
+	tokens.SLASH_EQUAL:   true,
+	tokens.PERCENT_EQUAL: true,
+	tokens.LSHIFT_EQUAL:  true,
+	tokens.RSHIFT_EQUAL:  true,
+	tokens.CARET_EQUAL:   true,
+	tokens.AMPER_EQUAL:   true,
+	tokens.VLINE_EQUAL:   true,
+	tokens.EQUALS:        true,
+	tokens.NOT_EQUALS:    true,
+	tokens.GREAT_EQUAL:   true,
+	tokens.LESS_EQUAL:    true,
+	tokens.LESS:          true,
+	tokens.GREAT:         true,
+	tokens.AND:           true,
+	tokens.OR:            true,
+	tokens.SOLIDUS:       true,
+	tokens.PERCENT:       true,
+	tokens.LSHIFT:        true,
+	tokens.RSHIFT:        true,
+}
+
+// Operators which are binary if has left operand, unary if not.
+var unaryOps = map[string]bool{
+	tokens.PLUS:  true,
+	tokens.MINUS: true,
+	tokens.VLINE: true,
+	tokens.CARET: true,
+	tokens.STAR:  true,
+	tokens.AMPER: true,
+}
+
+// Unary operators which are lexed as another operator if doubled.
+var doubledOps = map[string]bool{
+	tokens.PLUS:  true,
+	tokens.MINUS: true,
+	tokens.VLINE: true,
+	tokens.AMPER: true,
+}
+
+// layout is the spacing context of tokens of a line.
+type layout struct {
+	toks []Tok
+	// Line is header of struct, trait, impl or enum.
+	decl bool
+	// Line is header of function.
+	fn bool
+	// Indexes of matching braces in line, -1 if not matched.
+	pairs []int
+	// Innermost unclosed opener before token, empty if not exist.
+	inner []string
+}
+
+func newLayout(toks []Tok, decl, fn bool) *layout {
+	l := &layout{
+		toks:  toks,
+		decl:  decl,
+		fn:    fn,
+		pairs: make([]int, len(toks)),
+		inner: make([]string, len(toks)),
+	}
+	var openers []int
+	for i, tok := range toks {
+		l.pairs[i] = -1
+		if len(openers) > 0 {
+			l.inner[i] = toks[openers[len(openers)-1]].Kind
+		}
+		switch {
+		case isOpener(tok):
+			openers = append(openers, i)
+		case isCloser(tok) && len(openers) > 0:
+			j := openers[len(openers)-1]
+			openers = openers[:len(openers)-1]
+			l.pairs[i], l.pairs[j] = j, i
+		}
+	}
+	return l
+}
+
+// isKeyword reports token is keyword or not.
+func isKeyword(tok Tok) bool {
+	switch tok.Id {
+	case tokens.Ret, tokens.Const, tokens.Type, tokens.For, tokens.Break,
+		tokens.Continue, tokens.In, tokens.If, tokens.Else, tokens.Use,
+		tokens.Pub, tokens.Defer, tokens.Goto, tokens.Enum, tokens.Struct,
+		tokens.Match, tokens.Case, tokens.Default, tokens.Trait,
+		tokens.Impl, tokens.Cpp, tokens.Fallthrough:
+		return true
+	}
+	return false
+}
+
+// isWord reports token is keyword, identifier, data type or value.
+func isWord(tok Tok) bool {
+	switch tok.Id {
+	case tokens.Id, tokens.DataType, tokens.Value, tokens.Self:
+		return true
+	}
+	return isKeyword(tok)
+}
+
+// isOperand reports token is end of operand or not.
+func isOperand(tok Tok) bool {
+	switch tok.Id {
+	case tokens.Id, tokens.DataType, tokens.Value, tokens.Self:
+		return true
+	case tokens.Brace:
+		return isCloser(tok)
+	}
+	return false
+}
+
+func isBrace(tok Tok, kind string) bool {
+	return tok.Id == tokens.Brace && tok.Kind == kind
+}
+
+// typed reports toks[i] is in data type rather than expression.
+// Such as pointer or array of types of parameters and results
+// of functions, and components of array types.
+func (l *layout) typed(i int) bool {
+	if i == 0 {
+		return false
+	}
+	left := l.toks[i-1]
+	switch {
+	case isBrace(left, tokens.RBRACKET):
+		// Brackets of array type are not index of an operand.
+		j := l.pairs[i-1]
+		return j != -1 && (j == 0 || !isOperand(l.toks[j-1]) || l.typed(j))
+	case isBrace(left, tokens.RPARENTHESES) && !l.fn:
+		// Result types of anonymous functions are followed by body.
+		j := l.pairs[i-1]
+		end := l.pairs[i]
+		return isBrace(l.toks[i], tokens.LBRACKET) && j != -1 &&
+			(j == 0 || !isOperand(l.toks[j-1])) &&
+			end != -1 && end+1 < len(l.toks) && isBrace(l.toks[end+1], tokens.LBRACE)
+	case !l.fn:
+		return false
+	case isBrace(left, tokens.RPARENTHESES):
+		return true
+	case left.Id == tokens.Id:
+		// Identifier is name of parameter.
+		return i > 1 && (isBrace(l.toks[i-2], tokens.LPARENTHESES) || l.toks[i-2].Id == tokens.Comma)
+	}
+	return false
+}
+
+// binary reports toks[i] is binary operator or not.
+func (l *layout) binary(i int) bool {
+	op := l.toks[i]
+	if op.Id != tokens.Operator {
+		return false
+	}
+	if binaryOps[op.Kind] {
+		return true
+	}
+	return unaryOps[op.Kind] && i > 0 && isOperand(l.toks[i-1]) && !l.typed(i)
+}
+
+// isBlockKeyword reports token is head of statement which has block.
+func isBlockKeyword(tok Tok) bool {
+	switch tok.Id {
+	case tokens.If, tokens.Else, tokens.For, tokens.Match:
+		return true
+	}
+	return false
+}
+
+// block reports brace toks[i] is brace of block rather than literal.
+func (l *layout) block(i int) bool {
+	j := i
+	if isCloser(l.toks[i]) {
+		// Closer of block of previous lines.
+		if j = l.pairs[i]; j == -1 {
+			return true
+		}
+	}
+	if l.decl || j == 0 || l.fn && l.inner[j] == "" {
+		return true
+	}
+	left := l.toks[j-1]
+	switch {
+	case isKeyword(left), isBrace(left, tokens.RPARENTHESES):
+		return true
+	case j > 1 && isBrace(l.toks[j-2], tokens.RPARENTHESES) && isOperand(left):
+		// Result type of function.
+		return true
+	case isBrace(left, tokens.RBRACKET):
+		// Multiple result types of function.
+		if k := l.pairs[j-1]; k > 0 && isBrace(l.toks[k-1], tokens.RPARENTHESES) {
+			return true
+		}
+	}
+	// Literals are not last part of header of statement,
+	// block of statement follows them.
+	if !isBlockKeyword(head(l.toks)) || l.inner[j] != "" {
+		return false
+	}
+	end := l.pairs[j]
+	return end == -1 || end == len(l.toks)-1 ||
+		l.toks[end+1].Id == tokens.Else || l.toks[end+1].Id == tokens.Comment
+}
+
+// after returns spacing after toks[i].
+func (l *layout) after(i int) int {
+	tok := l.toks[i]
+	switch tok.Id {
+	case tokens.Comment, tokens.Comma, tokens.SemiColon:
+		return always
+	case tokens.Dot, tokens.DoubleColon, tokens.At, tokens.Preprocessor:
+		return never
+	case tokens.Colon:
+		// Key types of maps and ranges of slicing.
+		if l.inner[i] == tokens.LBRACKET {
+			return never
+		}
+		return always
+	case tokens.Brace:
+		switch tok.Kind {
+		case tokens.LPARENTHESES, tokens.LBRACKET:
+			return never
+		case tokens.LBRACE:
+			// Empty braces are never spaced.
+			if !l.block(i) || i+1 < len(l.toks) && isBrace(l.toks[i+1], tokens.RBRACE) {
+				return never
+			}
+			return always
+		}
+	case tokens.Operator:
+		switch {
+		case l.binary(i):
+			return always
+		case tok.Kind == tokens.TRIPLE_DOT:
+			return never
+		case tok.Kind == tokens.EXCLAMATION, unaryOps[tok.Kind]:
+			if i+1 < len(l.toks) && doubledOps[tok.Kind] && l.toks[i+1].Kind == tok.Kind {
+				return always
+			}
+			return never
+		}
+	}
+	return unset
+}
+
+// before returns spacing before toks[i].
+func (l *layout) before(i int) int {
+	tok := l.toks[i]
+	left := l.toks[i-1]
+	switch tok.Id {
+	case tokens.Comment:
+		return always
+	case tokens.Id, tokens.DataType:
+		switch {
+		// Return type of function.
+		case isBrace(left, tokens.RPARENTHESES):
+			return always
+		// Component of array type.
+		case isBrace(left, tokens.RBRACKET):
+			return never
+		}
+	case tokens.Comma:
+		// Omitted clauses of for iterations.
+		if left.Id == tokens.For || left.Id == tokens.Comma {
+			return always
+		}
+		return never
+	case tokens.SemiColon, tokens.Colon, tokens.DoubleColon:
+		return never
+	case tokens.Dot:
+		if isOperand(left) || left.Id == tokens.Cpp {
+			return never
+		}
+	case tokens.Brace:
+		switch tok.Kind {
+		case tokens.RPARENTHESES, tokens.RBRACKET:
+			return never
+		case tokens.LPARENTHESES:
+			if isOperand(left) {
+				return never
+			}
+		case tokens.LBRACKET:
+			switch {
+			case l.typed(i):
+				return l.typeSpacing(i)
+			case isOperand(left), left.Id == tokens.Type:
+				// Indexes and generics.
+				return never
+			}
+		case tokens.LBRACE:
+			switch {
+			case l.block(i):
+				return always
+			case isOperand(left):
+				return never
+			}
+		case tokens.RBRACE:
+			if !l.block(i) || isBrace(left, tokens.LBRACE) {
+				return never
+			}
+			return always
+		}
+	case tokens.Operator:
+		switch {
+		case tok.Kind == tokens.DOUBLE_PLUS, tok.Kind == tokens.DOUBLE_MINUS:
+			return never
+		case l.binary(i):
+			return always
+		case l.typed(i):
+			return l.typeSpacing(i)
+		case tok.Kind == tokens.TRIPLE_DOT && isOperand(left):
+			// Spreading of operand, not variadic parameter.
+			return never
+		}
+	}
+	return unset
+}
+
+// typeSpacing returns spacing before toks[i] which is in data type.
+// Data type is separated from name of parameter and parameters,
+// but not from brackets of array type.
+func (l *layout) typeSpacing(i int) int {
+	if isBrace(l.toks[i-1], tokens.RBRACKET) {
+		return never
+	}
+	return always
+}
+
+// space reports space is needed between toks[i-1] and toks[i] or not.
+// Words are separated and other tokens are joined if sides of
+// tokens are not decide spacing.
+func (l *layout) space(i int) bool {
+	a, b := l.after(i-1), l.before(i)
+	switch {
+	case a == never, b == never:
+		return false
+	case a == always, b == always:
+		return true
+	}
+	return isWord(l.toks[i-1]) || isWord(l.toks[i])
+}
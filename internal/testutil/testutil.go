@@ -0,0 +1,56 @@
+// Package testutil provides the common setup of tests of packages.
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/the-xlang/xxc/pkg/x"
+	"github.com/the-xlang/xxc/pkg/xset"
+)
+
+// Root returns path of root directory of repository,
+// which is the nearest directory which has go.mod file.
+func Root() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			panic("root of repository is not found")
+		}
+		dir = parent
+	}
+}
+
+// Main runs tests of package with standard library and
+// localizations of repository, and default X settings.
+// Tests could change settings, they are not shared with defaults.
+func Main(m *testing.M) {
+	root := Root()
+	x.StdlibPath = filepath.Join(root, x.Stdlib)
+	x.LangsPath = filepath.Join(root, x.Localizations)
+	set := *xset.Default
+	x.Set = &set
+	os.Exit(m.Run())
+}
+
+// WriteFiles writes files to directory by paths relative to it.
+func WriteFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, text := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -26,6 +26,10 @@ type Lex struct {
 	Row    int
 	// Logs are only errors
 	Logs []xlog.CompilerLog
+	// KeepComments lexes all comments as tokens.
+	// Normally, only comments at first of lines are tokens
+	// and range comments are skipped.
+	KeepComments bool
 
 	braces []Tok
 }
@@ -47,6 +51,8 @@ func (l *Lex) pusherr(key string, args ...any) {
 		Column:  l.Column,
 		Path:    l.File.Path(),
 		Message: x.GetError(key, args...),
+		Key:     key,
+		Args:    args,
 	})
 }
 
@@ -55,8 +61,10 @@ func (l *Lex) pusherrtok(tok Tok, err string) {
 		Type:    xlog.Error,
 		Row:     tok.Row,
 		Column:  tok.Column,
+		Length:  tok.Length(),
 		Path:    l.File.Path(),
 		Message: x.GetError(err),
+		Key:     err,
 	})
 }
 
@@ -160,20 +168,21 @@ func (l *Lex) lncomment(tok *Tok) {
 	l.Pos += 2
 	for ; l.Pos < len(l.File.Data); l.Pos++ {
 		if l.File.Data[l.Pos] == '\n' {
-			if l.firstTokOfLine {
+			if l.firstTokOfLine || l.KeepComments {
 				tok.Id = tokens.Comment
 				tok.Kind = string(l.File.Data[start:l.Pos])
 			}
 			return
 		}
 	}
-	if l.firstTokOfLine {
+	if l.firstTokOfLine || l.KeepComments {
 		tok.Id = tokens.Comment
 		tok.Kind = string(l.File.Data[start:])
 	}
 }
 
-func (l *Lex) rangecomment() {
+func (l *Lex) rangecomment(tok *Tok) {
+	start := l.Pos
 	l.Pos += 2
 	for ; l.Pos < len(l.File.Data); l.Pos++ {
 		run := l.File.Data[l.Pos]
@@ -185,6 +194,10 @@ func (l *Lex) rangecomment() {
 		if strings.HasPrefix(string(l.File.Data[l.Pos:]), tokens.RANGE_COMMENT_CLOSE) {
 			l.Column += 2
 			l.Pos += 2
+			if l.KeepComments {
+				tok.Id = tokens.Comment
+				tok.Kind = string(l.File.Data[start:l.Pos])
+			}
 			return
 		}
 	}
@@ -478,7 +491,7 @@ func (l *Lex) Tok() Tok {
 		l.lncomment(&tok)
 		return tok
 	case strings.HasPrefix(txt, tokens.RANGE_COMMENT_OPEN):
-		l.rangecomment()
+		l.rangecomment(&tok)
 		return tok
 	case l.isop(txt, tokens.LPARENTHESES, tokens.Brace, &tok):
 		l.braces = append(l.braces, tok)
@@ -1,5 +1,7 @@
 package lex
 
+import "strings"
+
 // Tok is lexer token.
 type Tok struct {
 	File   *File
@@ -8,3 +10,11 @@ type Tok struct {
 	Kind   string
 	Id     uint8
 }
+
+// Length returns column count of token at first line of it.
+func (tok Tok) Length() int {
+	if i := strings.IndexByte(tok.Kind, '\n'); i != -1 {
+		return len(strings.TrimRight(tok.Kind[:i], "\r"))
+	}
+	return len(tok.Kind)
+}
@@ -0,0 +1,141 @@
+package lsp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/the-xlang/xxc/pkg/x"
+	"github.com/the-xlang/xxc/pkg/xlog"
+	"github.com/the-xlang/xxc/pkg/xset"
+)
+
+// settings is the loaded settings file of server.
+type settings struct {
+	path string
+	mod  time.Time
+	// Logs of loading errors.
+	logs []xlog.CompilerLog
+}
+
+// initialize records workspace folders of client.
+func (s *Server) initialize(params *initializeParams) {
+	s.roots = nil
+	add := func(uri string) {
+		if path, err := uriToPath(uri); err == nil {
+			s.roots = append(s.roots, path)
+		}
+	}
+	for _, folder := range params.WorkspaceFolders {
+		add(folder.Uri)
+	}
+	if len(s.roots) > 0 {
+		return
+	}
+	if params.RootUri != "" {
+		add(params.RootUri)
+	} else if params.RootPath != "" {
+		if path, err := filepath.Abs(params.RootPath); err == nil {
+			s.roots = append(s.roots, path)
+		}
+	}
+}
+
+// workspaceRoot returns the innermost workspace folder of path.
+// Returns empty string if path is not in any workspace folder.
+func (s *Server) workspaceRoot(path string) string {
+	root := ""
+	for _, dir := range s.roots {
+		if isIn(dir, path) && len(dir) > len(root) {
+			root = dir
+		}
+	}
+	return root
+}
+
+// project loads settings of project of document and sets project path.
+// Project is the directory of the nearest settings file above document.
+// Workspace folder of document is the project if there is no settings file,
+// and directory of document if document is not in any workspace folder.
+// Returns logs of settings errors.
+func (s *Server) project(path string) []xlog.CompilerLog {
+	dir := filepath.Dir(path)
+	file := s.SettingsFile
+	if file == "" {
+		file = findSettings(dir)
+	}
+	switch root := s.workspaceRoot(path); {
+	case file != "":
+		x.ProjectPath = filepath.Dir(file)
+	case root != "":
+		x.ProjectPath = root
+	default:
+		x.ProjectPath = dir
+	}
+	var mod time.Time
+	if file != "" {
+		if info, err := os.Stat(file); err == nil {
+			mod = info.ModTime()
+		}
+	}
+	if s.settings != nil && s.settings.path == file && s.settings.mod.Equal(mod) {
+		return s.settings.logs
+	}
+	s.settings = &settings{path: file, mod: mod}
+	load := s.LoadSettings
+	if load == nil {
+		load = loadSettings
+	}
+	if err := load(file); err != nil {
+		s.settings.logs = []xlog.CompilerLog{{
+			Type:    xlog.FlatError,
+			Message: x.GetError("settings_has_errors", err.Error()),
+			Key:     "settings_has_errors",
+			Args:    []any{err.Error()},
+		}}
+	}
+	return s.settings.logs
+}
+
+// loadSettings loads settings file.
+// Default settings are used if path is empty or settings has errors.
+func loadSettings(path string) error {
+	set := *xset.Default
+	x.Set = &set
+	if path == "" {
+		return nil
+	}
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	loaded, err := xset.Load(bytes)
+	if err != nil {
+		return err
+	}
+	x.Set = loaded
+	return nil
+}
+
+// findSettings returns path of the nearest settings file above dir.
+// Returns empty string if there is no settings file.
+func findSettings(dir string) string {
+	for {
+		path := filepath.Join(dir, x.SettingsFile)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// isIn reports path is dir or in dir.
+func isIn(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,599 @@
+// Package lsp implements language server of X
+// with language server protocol over JSON-RPC.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/the-xlang/xxc/parser"
+	"github.com/the-xlang/xxc/pkg/x"
+	"github.com/the-xlang/xxc/pkg/xio"
+	"github.com/the-xlang/xxc/pkg/xlog"
+)
+
+// Source of diagnostics.
+const diagnosticSource = "xxc"
+
+// Width of tab character in columns of compiler logs.
+const tabWidth = 4
+
+// document is an open text document of client.
+type document struct {
+	path string
+	text string
+	// References of last compilation of document.
+	refs *parser.Refs
+	// Directories of files which are compiled with document.
+	deps []string
+}
+
+// Server is a language server.
+type Server struct {
+	// SettingsFile is the settings file of every document if not empty.
+	// Otherwise, settings file of document is the nearest one above document.
+	SettingsFile string
+	// LoadSettings loads settings file for compilation of document.
+	// Path is empty if document has not settings file.
+	// Server loads settings without overrides if nil.
+	LoadSettings func(path string) error
+
+	r    *bufio.Reader
+	w    io.Writer
+	docs map[string]*document
+	// Paths which diagnostics published for by path of document.
+	published map[string][]string
+	// Workspace folders of client.
+	roots    []string
+	settings *settings
+	shutdown bool
+}
+
+// New returns new language server which reads
+// messages from r and writes messages to w.
+func New(r io.Reader, w io.Writer) *Server {
+	return &Server{
+		r:         bufio.NewReader(r),
+		w:         w,
+		docs:      map[string]*document{},
+		published: map[string][]string{},
+	}
+}
+
+// Serve handles messages until exit notification.
+// Returns error if reading or writing is failed or
+// exit notification is received without shutdown request.
+func (s *Server) Serve() error {
+	for {
+		content, err := readMessage(s.r)
+		if err != nil {
+			if err == io.EOF {
+				err = errors.New("connection is closed before exit notification")
+			}
+			return err
+		}
+		var msg message
+		if err := json.Unmarshal(content, &msg); err != nil {
+			err = s.replyErr(json.RawMessage("null"), codeParseError, err.Error())
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("exit notification is received before shutdown request")
+			}
+			return nil
+		}
+		if err := s.handle(&msg); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) reply(id json.RawMessage, result any) error {
+	return writeMessage(s.w, response{Jsonrpc: "2.0", Id: id, Result: result})
+}
+
+func (s *Server) replyErr(id json.RawMessage, code int, msg string) error {
+	return writeMessage(s.w, errorResponse{
+		Jsonrpc: "2.0",
+		Id:      id,
+		Error:   responseError{Code: code, Message: msg},
+	})
+}
+
+func (s *Server) notify(method string, params any) error {
+	return writeMessage(s.w, notification{Jsonrpc: "2.0", Method: method, Params: params})
+}
+
+// handle handles request or notification.
+// Returns error only if writing is failed.
+func (s *Server) handle(msg *message) error {
+	isRequest := msg.Id != nil
+	if isRequest && s.shutdown {
+		return s.replyErr(msg.Id, codeInvalidRequest, "server is shut down")
+	}
+	var result any
+	switch msg.Method {
+	case "initialize":
+		var params initializeParams
+		if len(msg.Params) > 0 {
+			if err := json.Unmarshal(msg.Params, &params); err != nil {
+				return s.invalidParams(msg, err)
+			}
+		}
+		s.initialize(&params)
+		result = initializeResult{
+			Capabilities: serverCapabilities{
+				TextDocumentSync:   syncFull,
+				HoverProvider:      true,
+				DefinitionProvider: true,
+				CompletionProvider: completionOptions{
+					TriggerCharacters: []string{".", ":"},
+				},
+			},
+			ServerInfo: serverInfo{Name: "xxc", Version: x.Version},
+		}
+	case "shutdown":
+		s.shutdown = true
+	case "textDocument/didOpen":
+		var params didOpenTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return s.invalidParams(msg, err)
+		}
+		return s.update(params.TextDocument.Uri, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params didChangeTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return s.invalidParams(msg, err)
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil
+		}
+		// Documents are synchronized fully, last change is the content.
+		text := params.ContentChanges[len(params.ContentChanges)-1].Text
+		return s.update(params.TextDocument.Uri, text)
+	case "textDocument/didClose":
+		var params didCloseTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return s.invalidParams(msg, err)
+		}
+		return s.close(params.TextDocument.Uri)
+	case "textDocument/hover":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return s.invalidParams(msg, err)
+		}
+		result = s.hover(&params)
+	case "textDocument/definition":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return s.invalidParams(msg, err)
+		}
+		result = s.definition(&params)
+	case "textDocument/completion":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return s.invalidParams(msg, err)
+		}
+		result = s.completion(&params)
+	default:
+		if !isRequest {
+			// Unsupported notifications are ignored.
+			return nil
+		}
+		return s.replyErr(msg.Id, codeMethodNotFound, "method not found: "+msg.Method)
+	}
+	if !isRequest {
+		return nil
+	}
+	return s.reply(msg.Id, result)
+}
+
+// invalidParams replies error for invalid parameters of request.
+// Notifications with invalid parameters are ignored.
+func (s *Server) invalidParams(msg *message, err error) error {
+	if msg.Id == nil {
+		return nil
+	}
+	return s.replyErr(msg.Id, codeInvalidParams, err.Error())
+}
+
+// update sets text of document, compiles it and
+// publishes diagnostics of compilation.
+func (s *Server) update(uri, text string) error {
+	path, err := uriToPath(uri)
+	if err != nil {
+		// Documents which are not file are not compiled.
+		return nil
+	}
+	doc := &document{path: path, text: text}
+	s.docs[path] = doc
+	// Other documents are compiled with unsaved text of document.
+	xio.Overlay[path] = text
+	if err := s.compile(doc); err != nil {
+		return err
+	}
+	return s.compileDependents(path)
+}
+
+// compile compiles document and publishes diagnostics of compilation.
+func (s *Server) compile(doc *document) error {
+	var logs []xlog.CompilerLog
+	logs = append(logs, s.project(doc.path)...)
+	logs = append(logs, doc.compile()...)
+	return s.publish(doc, logs)
+}
+
+// compileDependents compiles open documents which are
+// compiled with file of path and publishes diagnostics of them.
+func (s *Server) compileDependents(path string) error {
+	dir := filepath.Dir(path)
+	paths := make([]string, 0, len(s.docs))
+	for docPath, doc := range s.docs {
+		if docPath != path && doc.dependsOn(dir) {
+			paths = append(paths, docPath)
+		}
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if err := s.compile(s.docs[path]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) close(uri string) error {
+	path, err := uriToPath(uri)
+	if err != nil {
+		return nil
+	}
+	doc := s.docs[path]
+	if doc == nil {
+		return nil
+	}
+	delete(s.docs, path)
+	delete(xio.Overlay, path)
+	err = s.publish(doc, nil)
+	delete(s.published, path)
+	if err != nil {
+		return err
+	}
+	// Saved content of file is used instead of unsaved text now.
+	return s.compileDependents(path)
+}
+
+// file returns source file of document with unsaved text.
+func (doc *document) file() *xio.File {
+	f := &xio.File{Data: []rune(doc.text)}
+	f.Dir, f.Name = filepath.Split(doc.path)
+	return f
+}
+
+// dependsOn reports document is compiled with files of dir.
+func (doc *document) dependsOn(dir string) bool {
+	for _, dep := range doc.deps {
+		if dep == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// compile compiles document with unsaved text and records references.
+// Returns warnings and errors of compilation.
+func (doc *document) compile() (logs []xlog.CompilerLog) {
+	p := parser.New(doc.file())
+	p.Refs = new(parser.Refs)
+	doc.refs = p.Refs
+	doc.deps = []string{filepath.Dir(doc.path)}
+	defer func() {
+		for _, dir := range parser.UsedPackages() {
+			doc.deps = append(doc.deps, filepath.Clean(dir))
+		}
+	}()
+	defer func() {
+		// Compiler may panic for broken source code in editing,
+		// server must stay alive.
+		if err := recover(); err != nil {
+			logs = append(logs, xlog.CompilerLog{
+				Type:    xlog.FlatError,
+				Message: x.GetError("compiler_crashed", err),
+				Key:     "compiler_crashed",
+				Args:    []any{err},
+			})
+		}
+	}()
+	parser.Reset()
+	p.Parsef(false, false)
+	logs = append(logs, p.Warnings...)
+	logs = append(logs, p.Errors...)
+	return logs
+}
+
+// publish publishes diagnostics of logs by files.
+// Diagnostics of files which are published previously
+// for document and have not logs now are cleared.
+func (s *Server) publish(doc *document, logs []xlog.CompilerLog) error {
+	diags := map[string][]diagnostic{}
+	paths := []string{doc.path}
+	diags[doc.path] = []diagnostic{}
+	for i := range logs {
+		log := &logs[i]
+		path := doc.path
+		if !log.IsFlat() && log.Path != "" {
+			path = filepath.Clean(log.Path)
+		}
+		if _, ok := diags[path]; !ok {
+			paths = append(paths, path)
+		}
+		diags[path] = append(diags[path], s.diagnostic(log, path))
+	}
+	for _, path := range s.published[doc.path] {
+		if _, ok := diags[path]; !ok {
+			paths = append(paths, path)
+			diags[path] = []diagnostic{}
+		}
+	}
+	s.published[doc.path] = paths
+	for _, path := range paths {
+		params := publishDiagnosticsParams{
+			Uri:         pathToUri(path),
+			Diagnostics: diags[path],
+		}
+		if err := s.notify("textDocument/publishDiagnostics", params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) diagnostic(log *xlog.CompilerLog, path string) diagnostic {
+	d := diagnostic{
+		Severity: severityWarning,
+		Code:     log.Code(),
+		Source:   diagnosticSource,
+		Message:  log.Message,
+	}
+	if log.IsError() {
+		d.Severity = severityError
+	}
+	if !log.IsFlat() {
+		d.Range = s.rangeOf(path, log.Row, log.Column, log.Length)
+	}
+	for _, note := range log.Notes {
+		if note.Path == "" {
+			continue
+		}
+		path := filepath.Clean(note.Path)
+		d.RelatedInformation = append(d.RelatedInformation, diagnosticRelatedInformation{
+			Location: location{
+				Uri:   pathToUri(path),
+				Range: s.rangeOf(path, note.Row, note.Column, note.Length),
+			},
+			Message: note.Message,
+		})
+	}
+	return d
+}
+
+func (s *Server) hover(params *textDocumentPositionParams) *hover {
+	ref := s.refAt(params)
+	if ref == nil {
+		return nil
+	}
+	sym := ref.Symbol()
+	var value strings.Builder
+	value.WriteString("```x\n")
+	value.WriteString(sym.String())
+	value.WriteString("\n```")
+	if desc := strings.TrimSpace(sym.Desc); desc != "" {
+		value.WriteString("\n\n")
+		value.WriteString(desc)
+	}
+	r := s.rangeOf(ref.Tok.File.Path(), ref.Tok.Row, ref.Tok.Column, ref.Tok.Length())
+	return &hover{
+		Contents: markupContent{Kind: markupMarkdown, Value: value.String()},
+		Range:    &r,
+	}
+}
+
+func (s *Server) definition(params *textDocumentPositionParams) *location {
+	ref := s.refAt(params)
+	if ref == nil {
+		return nil
+	}
+	tok := ref.Symbol().Tok
+	if tok.File == nil {
+		// Built-in definitions are have not location.
+		return nil
+	}
+	path := tok.File.Path()
+	return &location{
+		Uri:   pathToUri(path),
+		Range: s.rangeOf(path, tok.Row, tok.Column, tok.Length()),
+	}
+}
+
+var completionKinds = map[string]int{
+	parser.SymbolVar:      completionVariable,
+	parser.SymbolGlobal:   completionVariable,
+	parser.SymbolField:    completionField,
+	parser.SymbolFunc:     completionFunction,
+	parser.SymbolMethod:   completionMethod,
+	parser.SymbolCppLink:  completionFunction,
+	parser.SymbolStruct:   completionStruct,
+	parser.SymbolTrait:    completionInterface,
+	parser.SymbolEnum:     completionEnum,
+	parser.SymbolEnumItem: completionEnumMember,
+	parser.SymbolType:     completionClass,
+	parser.SymbolNs:       completionModule,
+}
+
+func (s *Server) completion(params *textDocumentPositionParams) (list completionList) {
+	list.Items = []completionItem{}
+	path, err := uriToPath(params.TextDocument.Uri)
+	if err != nil {
+		return
+	}
+	doc := s.docs[path]
+	if doc == nil {
+		return
+	}
+	defer func() {
+		// Compiler may panic for incomplete code, there is no candidate.
+		if recover() != nil {
+			list.Items = []completionItem{}
+		}
+	}()
+	s.project(path)
+	line := params.Position.Line
+	column := lineColumn(s.line(path, line), params.Position.Character)
+	for _, sym := range parser.Complete(doc.file(), line+1, column) {
+		item := completionItem{
+			Label:  sym.Id,
+			Kind:   completionKinds[sym.Kind],
+			Detail: sym.String(),
+		}
+		if desc := strings.TrimSpace(sym.Desc); desc != "" {
+			item.Documentation = &markupContent{Kind: markupMarkdown, Value: desc}
+		}
+		list.Items = append(list.Items, item)
+	}
+	return
+}
+
+// refAt returns reference at position of document.
+// Returns nil if document is not open or there is no reference.
+func (s *Server) refAt(params *textDocumentPositionParams) *parser.Ref {
+	path, err := uriToPath(params.TextDocument.Uri)
+	if err != nil {
+		return nil
+	}
+	doc := s.docs[path]
+	if doc == nil || doc.refs == nil {
+		return nil
+	}
+	line := params.Position.Line
+	column := lineColumn(s.line(path, line), params.Position.Character)
+	return doc.refs.At(path, line+1, column)
+}
+
+// line returns line of file with zero-based index.
+// Text of open documents is used instead of file.
+func (s *Server) line(path string, i int) string {
+	var text string
+	if doc := s.docs[path]; doc != nil {
+		text = doc.text
+	} else {
+		bytes, err := os.ReadFile(path)
+		if err != nil {
+			return ""
+		}
+		text = string(bytes)
+	}
+	lines := strings.Split(text, "\n")
+	if i < 0 || i >= len(lines) {
+		return ""
+	}
+	return strings.TrimSuffix(lines[i], "\r")
+}
+
+// rangeOf returns range of compiler log position in file.
+func (s *Server) rangeOf(path string, row, column, length int) lspRange {
+	if row < 1 {
+		return lspRange{}
+	}
+	line := s.line(path, row-1)
+	if length < 1 {
+		length = 1
+	}
+	return lspRange{
+		Start: position{Line: row - 1, Character: character(line, column)},
+		End:   position{Line: row - 1, Character: character(line, column+length)},
+	}
+}
+
+// runeColumns returns column count of rune in compiler logs.
+func runeColumns(r rune) int {
+	if r == '\t' {
+		return tabWidth
+	}
+	return utf8.RuneLen(r)
+}
+
+// character returns UTF-16 offset of one-based column of compiler logs.
+func character(line string, column int) int {
+	col, char := 1, 0
+	for _, r := range line {
+		if col >= column {
+			break
+		}
+		col += runeColumns(r)
+		char += utf16Len(r)
+	}
+	if col < column {
+		// Column is after of line.
+		char += column - col
+	}
+	return char
+}
+
+// lineColumn returns one-based column of compiler logs of UTF-16 offset.
+func lineColumn(line string, char int) int {
+	col, offset := 1, 0
+	for _, r := range line {
+		if offset >= char {
+			break
+		}
+		col += runeColumns(r)
+		offset += utf16Len(r)
+	}
+	return col
+}
+
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+// uriToPath returns clean absolute path of file URI.
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", errors.New("unsupported uri scheme: " + u.Scheme)
+	}
+	path := u.Path
+	// Windows paths are have drive letter such as /C:/dir.
+	if runtime.GOOS == "windows" && len(path) > 2 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.Abs(filepath.FromSlash(path))
+}
+
+// pathToUri returns file URI of path.
+func pathToUri(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	u := url.URL{Scheme: "file", Path: path}
+	return u.String()
+}
@@ -0,0 +1,385 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/the-xlang/xxc/internal/testutil"
+	"github.com/the-xlang/xxc/pkg/x"
+)
+
+func TestMain(m *testing.M) { testutil.Main(m) }
+
+// serverMessage is a response or notification of server.
+type serverMessage struct {
+	Id     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *responseError  `json:"error"`
+}
+
+// client is a scripted client of language server.
+type client struct {
+	t      *testing.T
+	w      io.Writer
+	msgs   chan serverMessage
+	served chan error
+	id     int
+	// Last published diagnostics by uri.
+	diags map[string][]diagnostic
+}
+
+func newClient(t *testing.T) *client {
+	sr, cw := io.Pipe()
+	cr, sw := io.Pipe()
+	c := &client{
+		t:      t,
+		w:      cw,
+		msgs:   make(chan serverMessage, 64),
+		served: make(chan error, 1),
+		diags:  map[string][]diagnostic{},
+	}
+	go func() {
+		c.served <- New(sr, sw).Serve()
+		sw.Close()
+	}()
+	go func() {
+		defer close(c.msgs)
+		r := bufio.NewReader(cr)
+		for {
+			content, err := readMessage(r)
+			if err != nil {
+				return
+			}
+			var msg serverMessage
+			if json.Unmarshal(content, &msg) != nil {
+				return
+			}
+			c.msgs <- msg
+		}
+	}()
+	return c
+}
+
+func (c *client) write(msg any) {
+	c.t.Helper()
+	if err := writeMessage(c.w, msg); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *client) notify(method string, params any) {
+	c.t.Helper()
+	c.write(notification{Jsonrpc: "2.0", Method: method, Params: params})
+}
+
+// next returns next message of server.
+// Published diagnostics are recorded.
+func (c *client) next() serverMessage {
+	c.t.Helper()
+	select {
+	case msg, ok := <-c.msgs:
+		if !ok {
+			c.t.Fatalf("server is stopped: %v", <-c.served)
+		}
+		if msg.Method == "textDocument/publishDiagnostics" {
+			var params publishDiagnosticsParams
+			if err := json.Unmarshal(msg.Params, &params); err != nil {
+				c.t.Fatal(err)
+			}
+			c.diags[params.Uri] = params.Diagnostics
+		}
+		return msg
+	case <-time.After(time.Minute):
+		c.t.Fatal("server is not responding")
+	}
+	return serverMessage{}
+}
+
+// request sends request and returns response.
+// Messages before response are handled by next.
+func (c *client) request(method string, params any) serverMessage {
+	c.t.Helper()
+	c.id++
+	id, _ := json.Marshal(c.id)
+	c.write(struct {
+		Jsonrpc string          `json:"jsonrpc"`
+		Id      json.RawMessage `json:"id"`
+		Method  string          `json:"method"`
+		Params  any             `json:"params,omitempty"`
+	}{"2.0", id, method, params})
+	for {
+		if msg := c.next(); string(msg.Id) == string(id) {
+			return msg
+		}
+	}
+}
+
+// sync waits until server handles previous messages.
+// Server replies error for unknown requests after previous messages.
+func (c *client) sync() {
+	c.t.Helper()
+	c.request("$/sync", nil)
+}
+
+func (c *client) exit() {
+	c.t.Helper()
+	c.request("shutdown", nil)
+	c.notify("exit", nil)
+	if err := <-c.served; err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *client) open(path, text string) {
+	c.t.Helper()
+	c.notify("textDocument/didOpen", didOpenTextDocumentParams{
+		TextDocument: textDocumentItem{Uri: pathToUri(path), Text: text},
+	})
+	c.sync()
+}
+
+func (c *client) change(path, text string) {
+	c.t.Helper()
+	c.notify("textDocument/didChange", didChangeTextDocumentParams{
+		TextDocument:   textDocumentIdentifier{Uri: pathToUri(path)},
+		ContentChanges: []textDocumentContentChangeEvent{{Text: text}},
+	})
+	c.sync()
+}
+
+func (c *client) close(path string) {
+	c.t.Helper()
+	c.notify("textDocument/didClose", didCloseTextDocumentParams{
+		TextDocument: textDocumentIdentifier{Uri: pathToUri(path)},
+	})
+	c.sync()
+}
+
+// messages returns published diagnostic messages of file.
+func (c *client) messages(path string) []string {
+	var msgs []string
+	for _, d := range c.diags[pathToUri(path)] {
+		msgs = append(msgs, d.Code+": "+d.Message)
+	}
+	return msgs
+}
+
+// writeFiles writes files to temporary directory and returns directory.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, text := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+const validMain = `main() {
+	s: = "hello"
+	outln(s.len)
+}
+`
+
+func TestBrokenBuffers(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"main.xx": validMain})
+	path := filepath.Join(dir, "main.xx")
+	c := newClient(t)
+	c.request("initialize", nil)
+	c.open(path, validMain)
+	if msgs := c.messages(path); len(msgs) > 0 {
+		t.Fatalf("valid code has diagnostics: %q", msgs)
+	}
+	broken := []struct {
+		name string
+		text string
+		code string
+	}{
+		{"argument typo", "main() {\n\ts: = \"hello\"\n\toutln(s.x len)\n}\n", "X0005"},
+		{"alone attribute", "@\nmain() {}\n", "X0005"},
+		{"alone pragma", "# 1 pragma enofi\nmain() {}\n", "X0005"},
+		{"empty generics", "f(a :: b []int) {}\nmain() {}\n", "X0062"},
+		{"match without case", "main() {\n\tmatch 1 {\n\tcas = e 1:\n\t}\n}\n", "X0005"},
+		{"unclosed call", "main() {\n\toutln(\n}\n", ""},
+	}
+	for _, b := range broken {
+		c.change(path, b.text)
+		msgs := c.messages(path)
+		if len(msgs) == 0 {
+			t.Errorf("%s: no diagnostics", b.name)
+		} else if b.code != "" && !strings.HasPrefix(msgs[0], b.code) {
+			t.Errorf("%s: want %s diagnostic first, got %q", b.name, b.code, msgs)
+		}
+		for _, msg := range msgs {
+			if strings.HasPrefix(msg, x.ErrorCodes["compiler_crashed"]) {
+				t.Errorf("%s: compiler is crashed: %q", b.name, msg)
+			}
+		}
+	}
+	// Server must be alive and work with valid code again.
+	c.change(path, validMain)
+	if msgs := c.messages(path); len(msgs) > 0 {
+		t.Fatalf("valid code has diagnostics: %q", msgs)
+	}
+	resp := c.request("textDocument/hover", textDocumentPositionParams{
+		TextDocument: textDocumentIdentifier{Uri: pathToUri(path)},
+		Position:     position{Line: 2, Character: 7},
+	})
+	if resp.Error != nil || !strings.Contains(string(resp.Result), "str") {
+		t.Fatalf("hover of s is failed: %s %v", resp.Result, resp.Error)
+	}
+	c.exit()
+}
+
+func TestCompletionInExpression(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"main.xx": validMain})
+	path := filepath.Join(dir, "main.xx")
+	c := newClient(t)
+	c.request("initialize", nil)
+	c.open(path, validMain)
+	cases := []struct {
+		char int
+		want string
+	}{
+		{9, "len"},  // After dot, before identifier.
+		{10, "len"}, // In identifier after dot.
+		{8, "s"},    // End of operand.
+	}
+	for _, cc := range cases {
+		resp := c.request("textDocument/completion", textDocumentPositionParams{
+			TextDocument: textDocumentIdentifier{Uri: pathToUri(path)},
+			Position:     position{Line: 2, Character: cc.char},
+		})
+		var list completionList
+		if err := json.Unmarshal(resp.Result, &list); err != nil {
+			t.Fatal(err)
+		}
+		labels := map[string]bool{}
+		for _, item := range list.Items {
+			labels[item.Label] = true
+		}
+		if !labels[cc.want] {
+			t.Errorf("character %d: missing %s in %v", cc.char, cc.want, labels)
+		}
+	}
+	c.exit()
+}
+
+func TestProjectRoot(t *testing.T) {
+	const main = "use lib::{hidden}\n\nmain() {\n\toutln(hidden())\n}\n"
+	const lib = "pub hidden() int { ret 1 }\n"
+	cases := []struct {
+		name  string
+		files map[string]string
+		// Workspace folder relative to temporary directory.
+		root string
+		doc  string
+	}{
+		{
+			name:  "workspace root",
+			files: map[string]string{"app/main.xx": main, "lib/lib.xx": lib},
+			root:  ".",
+			doc:   "app/main.xx",
+		},
+		{
+			name:  "nearest settings file",
+			files: map[string]string{"x.set": "{}", "app/main.xx": main, "lib/lib.xx": lib},
+			doc:   "app/main.xx",
+		},
+		{
+			name: "settings file in workspace",
+			files: map[string]string{
+				"project/x.set":       "{}",
+				"project/app/main.xx": main,
+				"project/lib/lib.xx":  lib,
+			},
+			root: ".",
+			doc:  "project/app/main.xx",
+		},
+	}
+	for _, pc := range cases {
+		t.Run(pc.name, func(t *testing.T) {
+			dir := writeFiles(t, pc.files)
+			path := filepath.Join(dir, pc.doc)
+			var params initializeParams
+			if pc.root != "" {
+				params.RootUri = pathToUri(filepath.Join(dir, pc.root))
+			}
+			c := newClient(t)
+			c.request("initialize", params)
+			c.open(path, pc.files[pc.doc])
+			if msgs := c.messages(path); len(msgs) > 0 {
+				t.Errorf("package of project is not used: %q", msgs)
+			}
+			c.exit()
+		})
+	}
+}
+
+func TestSettingsErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"x.set": "{", "main.xx": validMain})
+	path := filepath.Join(dir, "main.xx")
+	c := newClient(t)
+	c.request("initialize", nil)
+	c.open(path, validMain)
+	code := x.ErrorCodes["settings_has_errors"]
+	if msgs := c.messages(path); len(msgs) != 1 || !strings.HasPrefix(msgs[0], code) {
+		t.Errorf("want %s diagnostic, got %q", code, msgs)
+	}
+	c.exit()
+}
+
+func TestDependentDocuments(t *testing.T) {
+	const main = "use lib::{hidden}\n\nmain() {\n\toutln(hidden() + helper())\n}\n"
+	const lib = "pub hidden() int { ret 1 }\n"
+	const helper = "helper() int { ret 2 }\n"
+	dir := writeFiles(t, map[string]string{
+		"x.set":       "{}",
+		"app/main.xx": main,
+		"app/util.xx": "",
+		"lib/lib.xx":  lib,
+	})
+	mainPath := filepath.Join(dir, "app", "main.xx")
+	utilPath := filepath.Join(dir, "app", "util.xx")
+	libPath := filepath.Join(dir, "lib", "lib.xx")
+	c := newClient(t)
+	c.request("initialize", nil)
+	c.open(mainPath, main)
+	if msgs := c.messages(mainPath); len(msgs) == 0 {
+		t.Fatal("saved file of package is not used: helper is defined")
+	}
+	steps := []struct {
+		name string
+		do   func()
+		// Main document has diagnostics or not.
+		fail bool
+	}{
+		{"open sibling with unsaved definition", func() { c.open(utilPath, helper) }, false},
+		{"open package with unsaved removal", func() { c.open(libPath, "pub shown() int { ret 1 }\n") }, true},
+		{"change package back", func() { c.change(libPath, lib) }, false},
+		{"close sibling without saving", func() { c.close(utilPath) }, true},
+	}
+	for _, step := range steps {
+		step.do()
+		msgs := c.messages(mainPath)
+		if step.fail && len(msgs) == 0 {
+			t.Errorf("%s: diagnostics of main are not republished", step.name)
+		} else if !step.fail && len(msgs) > 0 {
+			t.Errorf("%s: main has diagnostics: %q", step.name, msgs)
+		}
+	}
+	c.exit()
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/the-xlang/xxc/pkg/x"
+	"github.com/the-xlang/xxc/pkg/xio"
+)
+
+func TestAllowByFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.xx": "struct s {}\n\n#pragma allow doc_ignored\n// doc: ignored\nimpl s {}\n\nmain() {}\n",
+		// Same rows with main, but directive of main is not allows them.
+		"other.xx": "struct t {}\n\n\n// doc: ignored\nimpl t {}\n",
+		"whole.xx": "#pragma allow doc_ignored\n\nstruct u {}\n\n// doc: ignored\nimpl u {}\n",
+	}
+	for name, text := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer func(path string) { x.ProjectPath = path }(x.ProjectPath)
+	x.ProjectPath = dir
+	Reset()
+	f, err := xio.Openfx(filepath.Join(dir, "main.xx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := New(f)
+	p.Parsef(true, false)
+	if len(p.Errors) > 0 {
+		t.Fatalf("unexpected errors: %+v", p.Errors)
+	}
+	warned := map[string]bool{}
+	for _, log := range p.Warnings {
+		if log.Key == "doc_ignored" {
+			warned[filepath.Base(log.Path)] = true
+		}
+	}
+	cases := []struct {
+		file string
+		want bool
+	}{
+		{"main.xx", false},
+		{"other.xx", true},
+		{"whole.xx", false},
+	}
+	for _, c := range cases {
+		if warned[c.file] != c.want {
+			t.Errorf("%s: warned is %v, want %v", c.file, warned[c.file], c.want)
+		}
+	}
+}
+
+func TestAllowUndefinedKey(t *testing.T) {
+	f := &xio.File{Dir: t.TempDir(), Name: "main.xx", Data: []rune("#pragma allow doc_ignord\n\nmain() {}\n")}
+	p := New(f)
+	p.NoLocalPkg = true
+	p.Parsef(true, false)
+	if len(p.Errors) != 1 || p.Errors[0].Key != "undefined_warning_key" || p.Errors[0].Column != 15 {
+		t.Fatalf("want undefined_warning_key error at column 15, got %+v", p.Errors)
+	}
+}
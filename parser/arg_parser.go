@@ -29,7 +29,7 @@ type pureArgParser struct {
 }
 
 func (pap *pureArgParser) buildArgs() {
-	pap.args.Src = make([]Arg, len(*pap.pmap))
+	pap.args.Src = make([]Arg, len(pap.f.Params))
 	for i, p := range pap.f.Params {
 		pair := (*pap.pmap)[p.Id]
 		switch {
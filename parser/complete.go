@@ -0,0 +1,391 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/the-xlang/xxc/ast"
+	"github.com/the-xlang/xxc/ast/models"
+	"github.com/the-xlang/xxc/lex"
+	"github.com/the-xlang/xxc/lex/tokens"
+	"github.com/the-xlang/xxc/pkg/xapi"
+	"github.com/the-xlang/xxc/pkg/xtype"
+)
+
+// Identifier of cursor for parse the incomplete code.
+// It is inserted at cursor if there is no identifier to complete.
+const cursorId = "__xxc_cursor"
+
+var closingBraces = map[string]string{
+	tokens.LPARENTHESES: tokens.RPARENTHESES,
+	tokens.LBRACKET:     tokens.RBRACKET,
+	tokens.LBRACE:       tokens.RBRACE,
+}
+
+// completion is the cursor of completion and
+// block definitions which are visible at cursor.
+type completion struct {
+	row    int
+	column int
+	// Token of statement which definitions are captured at last.
+	tok   Tok
+	vars  []*Var
+	types []*Type
+}
+
+// before reports token is starts before cursor or at cursor.
+func (c *completion) before(tok Tok) bool {
+	return tok.Row < c.row || (tok.Row == c.row && tok.Column <= c.column)
+}
+
+// capture records block definitions if statement is starts
+// before cursor in the function which is includes cursor.
+// Last captured definitions are the visible definitions at cursor
+// because statements are checked by order.
+func (c *completion) capture(p *Parser, tok Tok) {
+	if c == nil || tok.File != p.File || !c.before(tok) || p.rootBlock == nil {
+		return
+	}
+	f := p.rootBlock.Func
+	if f == nil || f.Tok.File != p.File || !c.before(f.Tok) {
+		return
+	}
+	end := f.Block.End
+	if end.File != nil && (end.Row < c.row || (end.Row == c.row && end.Column < c.column)) {
+		return
+	}
+	c.tok = tok
+	c.vars = append(c.vars[:0], p.blockVars...)
+	c.types = append(c.types[:0], p.blockTypes...)
+}
+
+// captureEnd records block definitions at end of root block if cursor
+// is at a line after last statement. Statement of cursor may be not built
+// for incomplete code, such as if statement without block.
+// Definitions of sub blocks are kept if they are captured at last.
+func (c *completion) captureEnd(p *Parser, b *models.Block) {
+	if c == nil || b != p.rootBlock || len(b.Tree) == 0 {
+		return
+	}
+	if last := b.Tree[len(b.Tree)-1].Tok; last == c.tok && last.Row < c.row {
+		c.capture(p, last)
+	}
+}
+
+// completionContext is the code before cursor.
+type completionContext struct {
+	// Prefix of identifier to complete.
+	prefix string
+	// Dot or double colon before identifier, zero if there is no.
+	sep Tok
+	// Operand of separator.
+	operand Toks
+}
+
+// operandStart returns start index of operand which is ends at i.
+// Operand is not continues to previous line out of braces
+// because statements are separated by lines.
+func operandStart(toks Toks, i int) int {
+	braces := 0
+	for ; i >= 0; i-- {
+		tok := toks[i]
+		switch {
+		case braces == 0 && i+1 < len(toks) && tok.Row != toks[i+1].Row:
+			return i + 1
+		case tok.Id == tokens.Brace:
+			switch tok.Kind {
+			case tokens.RPARENTHESES, tokens.RBRACKET, tokens.RBRACE:
+				braces++
+			default:
+				if braces == 0 {
+					return i + 1
+				}
+				braces--
+			}
+		case braces > 0:
+		case tok.Id == tokens.Id, tok.Id == tokens.Self, tok.Id == tokens.Value,
+			tok.Id == tokens.DataType, tok.Id == tokens.Dot, tok.Id == tokens.DoubleColon:
+		default:
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// cursorToks returns tokens with identifier at cursor and context of cursor.
+// Returns false if cursor is in a token which is not identifier,
+// such as comments and literals.
+func cursorToks(f *File, toks Toks, row, column int) (Toks, completionContext, bool) {
+	var ctx completionContext
+	i := 0
+	for i < len(toks) && (toks[i].Row < row || (toks[i].Row == row && toks[i].Column < column)) {
+		i++
+	}
+	// Index of identifier of cursor.
+	idi := i
+	if i > 0 {
+		last := toks[i-1]
+		if last.Row == row && column <= last.Column+last.Length() {
+			// Keywords are may be prefix of identifiers.
+			if !isIdLike(last) {
+				// Comments are continues to end of line.
+				if column < last.Column+last.Length() || last.Id == tokens.Comment {
+					return nil, ctx, false
+				}
+			} else {
+				ctx.prefix = last.Kind[:column-last.Column]
+				idi = i - 1
+			}
+		}
+	}
+	if ctx.prefix == "" {
+		cursor := Tok{File: f, Row: row, Column: column, Id: tokens.Id, Kind: cursorId}
+		if i < len(toks) && toks[i].Row == row && toks[i].Column == column && isIdLike(toks[i]) {
+			// Identifier after cursor is replaced, cursor in front of
+			// it breaks expression such as the operand of dot.
+			toks = append(toks[:i:i], append(Toks{cursor}, toks[i+1:]...)...)
+		} else {
+			toks = append(toks[:i:i], append(Toks{cursor}, toks[i:]...)...)
+		}
+	}
+	// Separator is must be at same line, statements are separated by lines.
+	if idi > 0 && toks[idi-1].Row == row {
+		switch sep := toks[idi-1]; sep.Id {
+		case tokens.Dot, tokens.DoubleColon:
+			ctx.sep = sep
+			ctx.operand = toks[operandStart(toks, idi-2) : idi-1]
+		}
+	}
+	return toks, ctx, true
+}
+
+// isIdLike reports token is identifier or keyword.
+func isIdLike(tok Tok) bool {
+	if tok.Id == tokens.Value || tok.Id == tokens.Comment || tok.Kind == "" {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(tok.Kind)
+	return r == '_' || unicode.IsLetter(r)
+}
+
+// balanceBraces closes unclosed braces of incomplete code.
+// Parentheses and brackets which are opened at row
+// are closed at row, others are closed at end.
+func balanceBraces(toks Toks, row int) Toks {
+	if len(toks) == 0 {
+		return toks
+	}
+	balanced := make(Toks, 0, len(toks))
+	var opens Toks
+	close := func(at Tok, all bool) {
+		for len(opens) > 0 {
+			open := opens[len(opens)-1]
+			if !all && (open.Row != row || open.Kind == tokens.LBRACE) {
+				break
+			}
+			opens = opens[:len(opens)-1]
+			at.Id = tokens.Brace
+			at.Kind = closingBraces[open.Kind]
+			balanced = append(balanced, at)
+		}
+	}
+	for i, tok := range toks {
+		if i > 0 && tok.Row > row && toks[i-1].Row <= row {
+			prev := toks[i-1]
+			close(Tok{File: prev.File, Row: row, Column: prev.Column + prev.Length()}, false)
+		}
+		if tok.Id == tokens.Brace {
+			if _, ok := closingBraces[tok.Kind]; ok {
+				opens = append(opens, tok)
+			} else if len(opens) > 0 {
+				opens = opens[:len(opens)-1]
+			}
+		}
+		balanced = append(balanced, tok)
+	}
+	last := toks[len(toks)-1]
+	close(Tok{File: last.File, Row: last.Row + 1, Column: 1}, true)
+	return balanced
+}
+
+// Complete returns completion candidates at cursor of file.
+// Row and column are the position of cursor as compiler logs,
+// cursor is before the column. Code of file may be incomplete,
+// errors of code are ignored.
+//
+// Candidates are members of operand after dot, definitions of
+// namespace after double colon, and visible definitions otherwise.
+// Closest definitions are first.
+func Complete(f *File, row, column int) []Symbol {
+	toks := lex.NewLex(f).Lex()
+	toks, ctx, ok := cursorToks(f, toks, row, column)
+	if !ok {
+		return nil
+	}
+	b := ast.NewBuilder(balanceBraces(toks, row))
+	b.Build()
+	p := New(f)
+	p.completion = &completion{row: row, column: column}
+	p.Parset(b.Tree, false, false)
+	c := candidates{prefix: ctx.prefix, ids: map[string]bool{}}
+	switch ctx.sep.Id {
+	case tokens.Dot:
+		p.memberCandidates(&c, ctx)
+	case tokens.DoubleColon:
+		p.nsCandidates(&c, ctx.operand)
+	default:
+		p.scopeCandidates(&c)
+	}
+	return c.list
+}
+
+// candidates are the completion candidates with prefix.
+type candidates struct {
+	prefix string
+	list   []Symbol
+	ids    map[string]bool
+}
+
+// push appends symbol of definition if it is not shadowed.
+func (c *candidates) push(kind, id string, def any) {
+	if id == cursorId || xapi.IsIgnoreId(id) || c.ids[id] ||
+		!strings.HasPrefix(id, c.prefix) {
+		return
+	}
+	c.ids[id] = true
+	c.list = append(c.list, symbolOf(kind, def))
+}
+
+// pushDefs appends definitions which are accessible from file.
+// Variables and functions are fields and methods of members.
+func (c *candidates) pushDefs(dm *Defmap, f *File, members bool) {
+	accessible := func(tok Tok, pub bool) bool {
+		return tok.File == nil || isAccessable(f, tok.File, pub)
+	}
+	globalKind, funcKind := SymbolGlobal, SymbolFunc
+	if members {
+		globalKind, funcKind = SymbolField, SymbolMethod
+	}
+	for _, g := range dm.Globals {
+		if g != nil && accessible(g.Token, g.Pub) {
+			c.push(globalKind, g.Id, g)
+		}
+	}
+	for _, fn := range dm.Funcs {
+		if fn != nil && accessible(fn.Ast.Tok, fn.Ast.Pub) {
+			c.push(funcKind, fn.Ast.Id, fn)
+		}
+	}
+	for _, s := range dm.Structs {
+		if s != nil && accessible(s.Ast.Tok, s.Ast.Pub) {
+			c.push(SymbolStruct, s.Ast.Id, s)
+		}
+	}
+	for _, e := range dm.Enums {
+		if e != nil && accessible(e.Tok, e.Pub) {
+			c.push(SymbolEnum, e.Id, e)
+		}
+	}
+	for _, t := range dm.Traits {
+		if t != nil && accessible(t.Ast.Tok, t.Ast.Pub) {
+			c.push(SymbolTrait, t.Ast.Id, t)
+		}
+	}
+	for _, t := range dm.Types {
+		if t != nil && accessible(t.Tok, t.Pub) {
+			c.push(SymbolType, t.Id, t)
+		}
+	}
+	for _, ns := range dm.Namespaces {
+		if ns != nil {
+			c.push(SymbolNs, ns.Id, ns)
+		}
+	}
+	if dm.side != nil {
+		c.pushDefs(dm.side, f, members)
+	}
+}
+
+func (p *Parser) scopeCandidates(c *candidates) {
+	cp := p.completion
+	// Last declared block definitions are closest to cursor.
+	for i := len(cp.vars) - 1; i >= 0; i-- {
+		if v := cp.vars[i]; v != nil {
+			c.push(SymbolVar, v.Id, v)
+		}
+	}
+	for i := len(cp.types) - 1; i >= 0; i-- {
+		if t := cp.types[i]; t != nil {
+			c.push(SymbolType, t.Id, t)
+		}
+	}
+	c.pushDefs(p.Defs, p.File, false)
+	if p.allowBuiltin {
+		c.pushDefs(Builtin, p.File, false)
+	}
+}
+
+func (p *Parser) memberCandidates(c *candidates, ctx completionContext) {
+	operand := ctx.operand
+	if len(operand) == 0 {
+		// Dot without operand is accessor of self.
+		tok := ctx.sep
+		tok.Id = tokens.Self
+		tok.Kind = tokens.SELF
+		operand = Toks{tok}
+	}
+	p.blockVars = p.completion.vars
+	p.blockTypes = p.completion.types
+	val, _ := p.evalToks(operand)
+	checkType := val.data.Type
+	if typeIsExplicitPtr(checkType) {
+		checkType = unptrType(checkType)
+	}
+	switch {
+	case typeIsPure(checkType):
+		switch {
+		case checkType.Id == xtype.Str:
+			c.pushDefs(strDefs, p.File, true)
+		case valIsEnumType(val):
+			enum := val.data.Type.Tag.(*Enum)
+			for _, item := range enum.Items {
+				c.push(SymbolEnumItem, item.Id, enumItem{enum, item})
+			}
+		case valIsStructIns(val):
+			c.pushDefs(val.data.Type.Tag.(*xstruct).Defs, p.File, true)
+		case valIsTraitIns(val):
+			c.pushDefs(val.data.Type.Tag.(*trait).Defs, p.File, true)
+		}
+	case typeIsSlice(checkType):
+		c.pushDefs(sliceDefs, p.File, true)
+	case typeIsArray(checkType):
+		c.pushDefs(arrayDefs, p.File, true)
+	case typeIsMap(checkType):
+		readyMapDefs(checkType)
+		c.pushDefs(mapDefs, p.File, true)
+	}
+}
+
+func (p *Parser) nsCandidates(c *candidates, ids Toks) {
+	dm := p.Defs
+	for i, tok := range ids {
+		if i%2 != 0 {
+			if tok.Id != tokens.DoubleColon {
+				return
+			}
+			continue
+		}
+		if tok.Id != tokens.Id {
+			return
+		}
+		ns := dm.nsById(tok.Kind)
+		if ns == nil {
+			return
+		}
+		dm = ns.defs
+	}
+	if dm != p.Defs {
+		c.pushDefs(dm, p.File, false)
+	}
+}
@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/the-xlang/xxc/internal/testutil"
+	"github.com/the-xlang/xxc/pkg/xio"
+)
+
+func TestMain(m *testing.M) { testutil.Main(m) }
+
+// Mark of cursor in completion tests.
+const cursorMark = "|"
+
+// cursorFile returns file of code without cursor mark and
+// position of cursor mark as compiler logs.
+func cursorFile(t *testing.T, code string) (f *File, row, column int) {
+	t.Helper()
+	i := strings.Index(code, cursorMark)
+	if i < 0 {
+		t.Fatalf("missing cursor mark: %q", code)
+	}
+	before := code[:i]
+	row = strings.Count(before, "\n") + 1
+	column = 1
+	for _, r := range before[strings.LastIndex(before, "\n")+1:] {
+		if r == '\t' {
+			column += 4
+		} else {
+			column += utf8.RuneLen(r)
+		}
+	}
+	code = before + code[i+len(cursorMark):]
+	f = &xio.File{Dir: t.TempDir(), Name: "main.xx", Data: []rune(code)}
+	return f, row, column
+}
+
+func TestComplete(t *testing.T) {
+	cases := []struct {
+		name string
+		code string
+		// Candidates which must be exist.
+		want []string
+		// Candidates which must not be exist.
+		not []string
+	}{
+		{
+			name: "after dot",
+			code: "main() {\n\ts: = \"\"\n\toutln(s.|)\n}",
+			want: []string{"len", "find"},
+		},
+		{
+			name: "after dot before identifier",
+			code: "main() {\n\ts: = \"\"\n\toutln(s.|len)\n}",
+			want: []string{"len", "find"},
+		},
+		{
+			name: "in identifier after dot",
+			code: "main() {\n\ts: = \"\"\n\toutln(s.l|en)\n}",
+			want: []string{"len"},
+			not:  []string{"find"},
+		},
+		{
+			name: "after dot in binary expression",
+			code: "main() {\n\ts: = \"\"\n\tn: = s.len + s.|len * 2\n\t_ = n\n}",
+			want: []string{"len", "find"},
+		},
+		{
+			name: "after dot of call argument",
+			code: "main() {\n\ts: = \"\"\n\toutln(s.find(s.|, 0))\n}",
+			want: []string{"len"},
+		},
+		{
+			name: "field of struct",
+			code: "struct point {\n\tx: int\n\ty: int\n}\n\nmain() {\n\tp: = point{1, 2}\n\toutln(p.|x + 1)\n}",
+			want: []string{"x", "y"},
+		},
+		{
+			name: "before identifier in arguments",
+			code: "main() {\n\ttotal: = 1\n\toutln(|total)\n}",
+			want: []string{"total", "outln"},
+		},
+		{
+			name: "namespace before identifier",
+			code: "use std::math\n\nmain() {\n\toutln(std::math::|abs(-1))\n}",
+			want: []string{"abs", "PI"},
+		},
+		{
+			name: "in identifier of namespace",
+			code: "use std::math\n\nmain() {\n\toutln(std::math::a|bs(-1))\n}",
+			want: []string{"abs", "asin"},
+			not:  []string{"PI"},
+		},
+		{
+			name: "incomplete statement",
+			code: "main() {\n\ts: = \"\"\n\tif s.|\n}",
+			want: []string{"len"},
+		},
+		{
+			name: "incomplete assignment",
+			code: "main() {\n\ts: = \"\"\n\tn: = s.|\n}",
+			want: []string{"len"},
+		},
+		{
+			name: "incomplete condition",
+			code: "main() {\n\ts: = \"\"\n\tif s.| {\n\t}\n}",
+			want: []string{"len"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			f, row, column := cursorFile(t, c.code)
+			ids := map[string]bool{}
+			for _, sym := range Complete(f, row, column) {
+				ids[sym.Id] = true
+			}
+			for _, id := range c.want {
+				if !ids[id] {
+					t.Errorf("missing candidate %s in %v", id, ids)
+				}
+			}
+			for _, id := range c.not {
+				if ids[id] {
+					t.Errorf("unexpected candidate %s", id)
+				}
+			}
+		})
+	}
+}
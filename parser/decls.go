@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"sort"
+
+	"github.com/the-xlang/xxc/ast/models"
+)
+
+// Decl is a declaration of package.
+type Decl struct {
+	Symbol
+	Pub bool
+	// Kind and identifier of struct, trait or enum of member
+	// declarations, empty for declarations of package.
+	ScopeKind string
+	Scope     string
+}
+
+// decls are the declarations of package in directory.
+type decls struct {
+	dir  string
+	list []Decl
+}
+
+// push appends declaration if it is declared in package.
+// Definitions of used packages are may be in defines by selectors.
+func (d *decls) push(kind string, def any, pub bool, scopeKind, scope string) {
+	sym := symbolOf(kind, def)
+	if sym.Tok.File == nil || sym.Tok.File.Dir != d.dir {
+		return
+	}
+	d.list = append(d.list, Decl{Symbol: sym, Pub: pub, ScopeKind: scopeKind, Scope: scope})
+}
+
+func (d *decls) pushDefs(dm *Defmap) {
+	for _, f := range dm.Funcs {
+		d.push(SymbolFunc, f, f.Ast.Pub, "", "")
+	}
+	for _, s := range dm.Structs {
+		d.push(SymbolStruct, s, s.Ast.Pub, "", "")
+		for _, f := range s.Defs.Globals {
+			d.push(SymbolField, f, f.Pub, SymbolStruct, s.Ast.Id)
+		}
+		for _, f := range s.Defs.Funcs {
+			d.push(SymbolMethod, f, f.Ast.Pub, SymbolStruct, s.Ast.Id)
+		}
+	}
+	for _, t := range dm.Traits {
+		d.push(SymbolTrait, t, t.Ast.Pub, "", "")
+		// Functions of traits are public as traits.
+		for _, f := range t.Defs.Funcs {
+			d.push(SymbolMethod, f, t.Ast.Pub, SymbolTrait, t.Ast.Id)
+		}
+	}
+	for _, e := range dm.Enums {
+		d.push(SymbolEnum, e, e.Pub, "", "")
+		for _, item := range e.Items {
+			d.push(SymbolEnumItem, enumItem{e, item}, e.Pub, SymbolEnum, e.Id)
+		}
+	}
+	for _, t := range dm.Types {
+		d.push(SymbolType, t, t.Pub, "", "")
+	}
+	for _, g := range dm.Globals {
+		d.push(SymbolGlobal, g, g.Pub, "", "")
+	}
+}
+
+// Decls returns declarations of package of parser by order of positions.
+// Declarations of used packages are not included.
+// Cpp links are not public, they are visible in their files.
+func (p *Parser) Decls() []Decl {
+	d := decls{dir: p.File.Dir}
+	d.pushDefs(p.Defs)
+	links := append(append([]*models.CppLink(nil), p.cppLinks...), p.pkgCppLinks...)
+	for _, link := range links {
+		d.push(SymbolCppLink, link, false, "", "")
+	}
+	sort.SliceStable(d.list, func(i, j int) bool {
+		a, b := d.list[i].Tok, d.list[j].Tok
+		switch {
+		case a.File.Path() != b.File.Path():
+			return a.File.Path() < b.File.Path()
+		case a.Row != b.Row:
+			return a.Row < b.Row
+		}
+		return a.Column < b.Column
+	})
+	return d.list
+}
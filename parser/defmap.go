@@ -209,3 +209,22 @@ func (dm *Defmap) findById(id string, f *File) (int, *Defmap, byte) {
 	}
 	return -1, nil, ' '
 }
+
+// tokOf returns token of definition by index and type of findById.
+func (dm *Defmap) tokOf(i int, code byte) Tok {
+	switch code {
+	case 'g':
+		return dm.Globals[i].Token
+	case 'f':
+		return dm.Funcs[i].Ast.Tok
+	case 'e':
+		return dm.Enums[i].Tok
+	case 's':
+		return dm.Structs[i].Ast.Tok
+	case 't':
+		return dm.Types[i].Tok
+	case 'i':
+		return dm.Traits[i].Ast.Tok
+	}
+	return Tok{}
+}
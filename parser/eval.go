@@ -324,9 +324,10 @@ func (e *eval) callCppLink(data callData, m *exprModel) (v value) {
 	}
 	link := e.p.linkById(tok.Kind)
 	if link == nil {
-		e.pusherrtok(tok, "id_noexist", tok.Kind)
+		e.pushnoexist(tok, "id_noexist", e.p.linkIds())
 		return
 	}
+	e.p.pushref(tok, SymbolCppLink, link)
 	m.appendSubNode(exprNode{link.Link.Id})
 	return e.p.callFunc(link.Link, data, m)
 }
@@ -625,9 +626,10 @@ func (e *eval) castSlice(t, vt DataType, errtok Tok) {
 }
 
 func (e *eval) xTypeSubId(dm *Defmap, idTok Tok, m *exprModel) (v value) {
+	defs := dm
 	i, dm, t := dm.findById(idTok.Kind, nil)
 	if i == -1 {
-		e.pusherrtok(idTok, "obj_have_not_id", idTok.Kind)
+		e.pushnoexist(idTok, "obj_have_not_id", defs.ids("gfesti"))
 		return
 	}
 	v.lvalue = false
@@ -762,9 +764,10 @@ func (e *eval) typeId(toks Toks, m *exprModel) (v value) {
 }
 
 func (e *eval) xObjSubId(dm *Defmap, val value, idTok Tok, m *exprModel) (v value) {
+	defs := dm
 	i, dm, t := dm.findById(idTok.Kind, idTok.File)
 	if i == -1 {
-		e.pusherrtok(idTok, "obj_have_not_id", idTok.Kind)
+		e.pushnoexist(idTok, "obj_have_not_id", defs.ids("gf"))
 		return
 	}
 	v = val
@@ -773,6 +776,7 @@ func (e *eval) xObjSubId(dm *Defmap, val value, idTok Tok, m *exprModel) (v valu
 	case 'g':
 		g := dm.Globals[i]
 		g.Used = true
+		e.p.pushref(idTok, SymbolField, g)
 		v.data.Type = g.Type
 		v.lvalue = true
 		v.constExpr = g.Const
@@ -784,6 +788,7 @@ func (e *eval) xObjSubId(dm *Defmap, val value, idTok Tok, m *exprModel) (v valu
 	case 'f':
 		f := dm.Funcs[i]
 		f.used = true
+		e.p.pushref(idTok, SymbolMethod, f)
 		v.data.Type.Id = xtype.Func
 		v.data.Type.Tag = f.Ast
 		v.data.Type.Kind = f.Ast.DataTypeString()
@@ -827,8 +832,14 @@ func (e *eval) enumSubId(val value, idTok Tok, m *exprModel) (v value) {
 	v.isType = false
 	m.appendSubNode(exprNode{"::"})
 	m.appendSubNode(exprNode{xapi.OutId(idTok.Kind, enum.Tok.File)})
-	if enum.ItemById(idTok.Kind) == nil {
-		e.pusherrtok(idTok, "obj_have_not_id", idTok.Kind)
+	if item := enum.ItemById(idTok.Kind); item != nil {
+		e.p.pushref(idTok, SymbolEnumItem, enumItem{enum, item})
+	} else {
+		ids := make([]string, len(enum.Items))
+		for i, item := range enum.Items {
+			ids[i] = item.Id
+		}
+		e.pushnoexist(idTok, "obj_have_not_id", ids)
 	}
 	return
 }
@@ -856,6 +867,7 @@ func (e *eval) traitObjSubId(val value, idTok Tok, m *exprModel) value {
 
 type nsFind interface {
 	nsById(string) *namespace
+	nsIds() []string
 }
 
 func (e *eval) getNs(toks *Toks) *Defmap {
@@ -873,7 +885,7 @@ func (e *eval) getNs(toks *Toks) *Defmap {
 					*toks = (*toks)[i:]
 					return ns.defs
 				}
-				e.pusherrtok(tok, "namespace_not_exist", tok.Kind)
+				e.pushnoexist(tok, "namespace_not_exist", prev.nsIds())
 				return nil
 			}
 			prev = src.defs
@@ -0,0 +1,237 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/the-xlang/xxc/ast/models"
+	"github.com/the-xlang/xxc/lex/tokens"
+	"github.com/the-xlang/xxc/pkg/x"
+	"github.com/the-xlang/xxc/pkg/xapi"
+	"github.com/the-xlang/xxc/pkg/xio"
+	"github.com/the-xlang/xxc/pkg/xlog"
+	"github.com/the-xlang/xxc/pkg/xtype"
+)
+
+// pushfix appends new fix to the last error.
+func (p *Parser) pushfix(key string, edits ...xlog.Edit) {
+	if len(p.Errors) == 0 || len(edits) == 0 {
+		return
+	}
+	log := &p.Errors[len(p.Errors)-1]
+	log.Fixes = append(log.Fixes, xlog.Fix{
+		Message: x.GetNote(key),
+		Edits:   edits,
+	})
+}
+
+// sourceLine returns line of file at row without line break.
+func sourceLine(f *File, row int) string {
+	lines := strings.Split(string(f.Data), "\n")
+	if row < 1 || row > len(lines) {
+		return ""
+	}
+	return strings.TrimRight(lines[row-1], "\r")
+}
+
+// lineBreak returns line break of line of file at row.
+func lineBreak(f *File, row int) string {
+	lines := strings.Split(string(f.Data), "\n")
+	if row >= 1 && row <= len(lines) && strings.HasSuffix(lines[row-1], "\r") {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// indentOf returns indentation of line.
+func indentOf(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// columnOf returns column of end of text as lexer.
+func columnOf(text string) int {
+	column := 1
+	for _, r := range text {
+		if r == '\t' {
+			column += 4
+		} else {
+			column += len(string(r))
+		}
+	}
+	return column
+}
+
+// startsLine reports tok is the first token of line.
+func startsLine(tok Tok) bool {
+	return tok.Column == columnOf(indentOf(sourceLine(tok.File, tok.Row)))
+}
+
+func insertEdit(tok Tok, row, column int, text string) xlog.Edit {
+	return xlog.Edit{
+		Path:      tok.File.Path(),
+		Row:       row,
+		Column:    column,
+		EndRow:    row,
+		EndColumn: column,
+		Text:      text,
+	}
+}
+
+// removeLineEdit returns edit which removes line of token.
+func removeLineEdit(tok Tok) xlog.Edit {
+	return xlog.Edit{
+		Path:      tok.File.Path(),
+		Row:       tok.Row,
+		Column:    1,
+		EndRow:    tok.Row + 1,
+		EndColumn: 1,
+	}
+}
+
+// fixUnusedVar suggests ignoring value of unused variable,
+// or removing declaration if variable has not value.
+func (p *Parser) fixUnusedVar(v *Var) {
+	if v.Const || v.Token.File == nil || !startsLine(v.Token) {
+		return
+	}
+	line := sourceLine(v.Token.File, v.Token.Row)
+	indent := indentOf(line)
+	statement := line[len(indent):]
+	setter := strings.IndexByte(statement, '=')
+	switch {
+	case strings.Contains(statement, ";"):
+		return
+	case setter == -1:
+		p.pushfix("remove_declaration", removeLineEdit(v.Token))
+		return
+	case strings.Contains(statement[:setter], ","):
+		// Multiple declaration.
+		return
+	}
+	// Declaration before value is replaced with ignore assignment.
+	p.pushfix("ignore_value", xlog.Edit{
+		Path:      v.Token.File.Path(),
+		Row:       v.Token.Row,
+		Column:    v.Token.Column,
+		EndRow:    v.Token.Row,
+		EndColumn: columnOf(line[:len(indent)+setter+1]),
+		Text:      xapi.Ignore + " " + tokens.EQUAL,
+	})
+}
+
+// fixUnusedLine suggests removing line of unused declaration.
+// Line must be equal to statement with prefix.
+func (p *Parser) fixUnusedLine(tok Tok, statement string) {
+	line := strings.TrimSpace(sourceLine(tok.File, tok.Row))
+	if strings.HasPrefix(line, statement) && !strings.Contains(line, ";") {
+		p.pushfix("remove_declaration", removeLineEdit(tok))
+	}
+}
+
+// retValue returns expression of default value of type for return.
+// Reports false if type has not simple default value.
+func retValue(t DataType) (string, bool) {
+	if t.MultiTyped {
+		types, ok := t.Tag.([]DataType)
+		if !ok {
+			return "", false
+		}
+		values := make([]string, len(types))
+		for i, t := range types {
+			value, ok := retValue(t)
+			if !ok {
+				return "", false
+			}
+			values[i] = value
+		}
+		return strings.Join(values, ", "), true
+	}
+	if typeIsNilCompatible(t) {
+		return tokens.NIL, true
+	}
+	if !typeIsPure(t) {
+		return "", false
+	}
+	code := xtype.GetRealCode(t.Id)
+	if xtype.IsNumeric(code) || code == xtype.Bool || code == xtype.Str {
+		return xtype.DefaultValOfType(code), true
+	}
+	return "", false
+}
+
+// fixMissingRet suggests return statement at end of function.
+// Return values are default values of types if variables of results
+// are not declared.
+func (p *Parser) fixMissingRet(f *Func) {
+	if f.Block == nil || f.Block.End.File == nil || f.Block.End.Kind != tokens.RBRACE {
+		return
+	}
+	ret := tokens.RET
+	if !f.RetType.AnyVar() {
+		value, ok := retValue(f.RetType.Type)
+		if !ok {
+			return
+		}
+		ret += " " + value
+	}
+	end := f.Block.End
+	line := sourceLine(end.File, end.Row)
+	switch {
+	case startsLine(end):
+		indent := indentOf(line)
+		indent += strings.Repeat(x.Set.Indent, x.Set.IndentCount)
+		text := indent + ret + lineBreak(end.File, end.Row)
+		p.pushfix("add_ret", insertEdit(end, end.Row, 1, text))
+	case len(f.Block.Tree) == 0 && end.Row == f.Tok.Row:
+		// Empty block at same line such as "{}".
+		p.pushfix("add_ret", insertEdit(end, end.Row, end.Column, " "+ret+" "))
+	}
+}
+
+// fixUseAtContent suggests moving use declaration to the head of file.
+func (p *Parser) fixUseAtContent(tok Tok) {
+	line := sourceLine(tok.File, tok.Row)
+	if p.useRow == 0 || !startsLine(tok) || strings.Contains(line, ";") {
+		return
+	}
+	br := lineBreak(tok.File, tok.Row)
+	text := strings.TrimSpace(line) + br
+	if !p.headUses {
+		// Separate new use declarations from others.
+		text += br
+	}
+	remove := removeLineEdit(tok)
+	// Avoid two empty lines at old place.
+	if strings.TrimSpace(sourceLine(tok.File, tok.Row-1)) == "" &&
+		strings.TrimSpace(sourceLine(tok.File, tok.Row+1)) == "" {
+		remove.EndRow++
+	}
+	p.pushfix("move_use", insertEdit(tok, p.useRow, 1, text), remove)
+}
+
+// fixNotPub suggests pub modifier for definition of token.
+// Definitions out of project such as standard library are not changed.
+func (p *Parser) fixNotPub(tok Tok) {
+	if !xio.IsInProject(tok.File.Path()) {
+		return
+	}
+	line := sourceLine(tok.File, tok.Row)
+	indent := indentOf(line)
+	if strings.HasPrefix(line[len(indent):], tokens.AT) {
+		// Attributes are have to be before of modifier.
+		return
+	}
+	p.pushfix("add_pub", insertEdit(tok, tok.Row, columnOf(indent), tokens.PUB+" "))
+}
+
+// setUseRow sets row to insert use declarations before the last object
+// of head of file. Documentation comments of object are kept with object.
+func (p *Parser) setUseRow(head []models.Object) {
+	row := head[len(head)-1].Tok.Row
+	for i := len(head) - 2; i >= 0; i-- {
+		if _, ok := head[i].Data.(models.Comment); !ok || head[i].Tok.Row != row-1 {
+			break
+		}
+		row--
+	}
+	p.useRow = row
+}
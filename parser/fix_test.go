@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/the-xlang/xxc/pkg/x"
+	"github.com/the-xlang/xxc/pkg/xio"
+)
+
+func TestFixNotPubInProject(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.xx":    "use std::math::{xatan}\nuse lib::{hidden}\n\nmain() {\n\toutln(xatan(1.0))\n\toutln(hidden())\n}\n",
+		"lib/lib.xx": "hidden() int { ret 1 }\n",
+	}
+	for name, text := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer func(path string) { x.ProjectPath = path }(x.ProjectPath)
+	x.ProjectPath = dir
+	Reset()
+	f, err := xio.Openfx(filepath.Join(dir, "main.xx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := New(f)
+	p.Parsef(true, false)
+	fixes := map[string]int{}
+	for _, log := range p.Errors {
+		if log.Key == "id_not_pub" {
+			fixes[log.Args[0].(string)] = len(log.Fixes)
+		}
+	}
+	if n, ok := fixes["xatan"]; !ok || n != 0 {
+		t.Errorf("xatan of standard library: want error without fix, got %d fixes (reported: %v)", n, ok)
+	}
+	if n, ok := fixes["hidden"]; !ok || n != 1 {
+		t.Errorf("hidden of project: want error with fix, got %d fixes (reported: %v)", n, ok)
+	}
+}
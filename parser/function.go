@@ -14,6 +14,7 @@ type function struct {
 	used         bool
 	checked      bool
 	isEntryPoint bool
+	isTest       bool
 }
 
 func (f *function) outId() string {
@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/the-xlang/xxc/pkg/x"
+	"github.com/the-xlang/xxc/pkg/xset"
+)
+
+const lineSource = `g: = 5
+
+f() int {
+	ret g
+}
+
+main() {
+	v: = f()
+	outln(v)
+}
+`
+
+func TestLineDirectives(t *testing.T) {
+	defer func(set *xset.XSet) { x.Set = set }(x.Set)
+	set := *xset.Default
+	x.Set = &set
+	// Lines of generated code and rows of X code for them.
+	cases := []struct {
+		code string
+		row  string
+	}{
+		{"_g = i8_xt{5};", "1"},
+		{"_f(void) {", "3"},
+		{"return ", "4"},
+		{"void XID(main)(void) {", "7"},
+		{"int_xt XID(v) = ", "8"},
+		{"XID(outln)(XID(v));", "9"},
+	}
+	for _, enabled := range []bool{true, false} {
+		x.Set.LineDirectives = enabled
+		p := parseProject(t, map[string]string{"main.xx": lineSource})
+		if len(p.Errors) > 0 {
+			t.Fatalf("unexpected errors: %+v", p.Errors)
+		}
+		cpp := p.Cpp()
+		if !enabled {
+			if strings.Contains(cpp, "#line") {
+				t.Errorf("disabled line directives are written:\n%s", cpp)
+			}
+			continue
+		}
+		lines := strings.Split(cpp, "\n")
+		for _, c := range cases {
+			want := "#line " + c.row + ` "` + p.File.Path() + `"`
+			found := false
+			for i, line := range lines {
+				if i > 0 && strings.Contains(line, c.code) {
+					found = true
+					if lines[i-1] != want {
+						t.Errorf("line before %q is %q, want %q", line, lines[i-1], want)
+					}
+				}
+			}
+			if !found {
+				t.Errorf("%q is not generated:\n%s", c.code, cpp)
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/the-xlang/xxc/pkg/x"
+)
+
+// Position of log or note as file:row:column.
+type notePosition struct {
+	file   string
+	row    int
+	column int
+}
+
+func TestDeclarationNotes(t *testing.T) {
+	cases := []struct {
+		name  string
+		files map[string]string
+		key   string
+		err   notePosition
+		note  notePosition
+		// Key of message of note.
+		noteKey string
+	}{
+		{
+			name:    "function",
+			files:   map[string]string{"main.xx": "f() {}\n\nf() {}\n\nmain() {}\n"},
+			key:     "exist_id",
+			err:     notePosition{"main.xx", 3, 1},
+			note:    notePosition{"main.xx", 1, 1},
+			noteKey: "declared_here",
+		},
+		{
+			name: "function of other file",
+			files: map[string]string{
+				"main.xx":  "f() {}\n\nmain() {}\n",
+				"other.xx": "\nf() {}\n",
+			},
+			key:     "exist_id",
+			err:     notePosition{"other.xx", 2, 1},
+			note:    notePosition{"main.xx", 1, 1},
+			noteKey: "declared_here",
+		},
+		{
+			name:    "use",
+			files:   map[string]string{"main.xx": "use std::math\nuse std::math\n\nmain() {}\n"},
+			key:     "already_uses",
+			err:     notePosition{"main.xx", 2, 1},
+			note:    notePosition{"main.xx", 1, 1},
+			noteKey: "used_here",
+		},
+		{
+			name:    "label",
+			files:   map[string]string{"main.xx": "main() {\nl:\n\toutln(1)\nl:\n\tgoto l\n}\n"},
+			key:     "label_exist",
+			err:     notePosition{"main.xx", 4, 1},
+			note:    notePosition{"main.xx", 2, 1},
+			noteKey: "declared_here",
+		},
+		{
+			name:    "trait",
+			files:   map[string]string{"main.xx": "trait t {\n\tf()\n}\n\nstruct s {}\n\nimpl t for s {}\n\nmain() {}\n"},
+			key:     "notimpl_trait_def",
+			err:     notePosition{"main.xx", 7, 12},
+			note:    notePosition{"main.xx", 2, 5},
+			noteKey: "trait_def_here",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := parseProject(t, c.files)
+			for _, log := range p.Errors {
+				if log.Key != c.key {
+					continue
+				}
+				err := notePosition{filepath.Base(log.Path), log.Row, log.Column}
+				if err != c.err {
+					t.Errorf("error is at %v, want %v", err, c.err)
+				}
+				if len(log.Notes) != 1 {
+					t.Fatalf("got notes %+v, want one note", log.Notes)
+				}
+				n := log.Notes[0]
+				note := notePosition{filepath.Base(n.Path), n.Row, n.Column}
+				if note != c.note || n.Message != x.GetNote(c.noteKey) {
+					t.Errorf("note is %q at %v, want %q at %v", n.Message, note, x.GetNote(c.noteKey), c.note)
+				}
+				return
+			}
+			t.Fatalf("%s error is not reported: %+v", c.key, p.Errors)
+		})
+	}
+}
@@ -40,6 +40,28 @@ type RetType = models.RetType
 
 var used []*use
 
+// Reset drops packages of previous compilations,
+// they are parsed again by next compilation.
+func Reset() {
+	used = nil
+	useChain = nil
+}
+
+// UsedPackages returns directories of packages which are
+// used by compilations since last reset.
+func UsedPackages() []string {
+	dirs := make([]string, 0, len(used))
+	for _, use := range used {
+		if !use.cppLink {
+			dirs = append(dirs, use.Path)
+		}
+	}
+	return dirs
+}
+
+// useChain is the chain of use declarations in compilation.
+var useChain []*models.Use
+
 type waitingGlobal struct {
 	Var  *Var
 	Defs *Defmap
@@ -61,13 +83,20 @@ type Parser struct {
 	eval           *eval
 	allowBuiltin   bool
 	cppLinks       []*models.CppLink
+	pkgCppLinks    []*models.CppLink // Cpp links of other files of local package.
+	allows         preprocessor.Allows
+	useRow         int  // Row to insert use declarations, zero if unknown.
+	headUses       bool // There is use declarations at head of file.
+	completion     *completion
 
 	NoLocalPkg bool
 	JustDefs   bool
 	NoCheck    bool
 	IsMain     bool
+	Test       bool
 	Uses       []*use
 	Defs       *Defmap
+	Refs       *Refs
 	Errors     []xlog.CompilerLog
 	Warnings   []xlog.CompilerLog
 	File       *File
@@ -86,7 +115,16 @@ func New(f *File) *Parser {
 
 // pusherrtok appends new error by token.
 func (p *Parser) pusherrtok(tok Tok, key string, args ...any) {
-	p.pusherrmsgtok(tok, x.GetError(key, args...))
+	p.Errors = append(p.Errors, xlog.CompilerLog{
+		Type:    xlog.Error,
+		Row:     tok.Row,
+		Column:  tok.Column,
+		Length:  tok.Length(),
+		Path:    tok.File.Path(),
+		Message: x.GetError(key, args...),
+		Key:     key,
+		Args:    args,
+	})
 }
 
 // pusherrtok appends new error message by token.
@@ -95,19 +133,62 @@ func (p *Parser) pusherrmsgtok(tok Tok, msg string) {
 		Type:    xlog.Error,
 		Row:     tok.Row,
 		Column:  tok.Column,
+		Length:  tok.Length(),
 		Path:    tok.File.Path(),
 		Message: msg,
 	})
 }
 
+// pushwarnlog appends new warning if warning is not disabled or allowed.
+// Warning is appended as error if warnings are errors.
+func (p *Parser) pushwarnlog(log xlog.CompilerLog) {
+	for _, key := range x.Set.DisabledWarnings {
+		if key == log.Key {
+			return
+		}
+	}
+	if p.allows.Has(log.Key, log.Path, log.Row) {
+		return
+	}
+	if !x.Set.WarningsAsErrors {
+		p.Warnings = append(p.Warnings, log)
+		return
+	}
+	if log.IsFlat() {
+		log.Type = xlog.FlatError
+	} else {
+		log.Type = xlog.Error
+	}
+	p.Errors = append(p.Errors, log)
+}
+
 // pushwarntok appends new warning by token.
 func (p *Parser) pushwarntok(tok Tok, key string, args ...any) {
-	p.Warnings = append(p.Warnings, xlog.CompilerLog{
+	p.pushwarnlog(xlog.CompilerLog{
 		Type:    xlog.Warning,
 		Row:     tok.Row,
 		Column:  tok.Column,
+		Length:  tok.Length(),
 		Path:    tok.File.Path(),
 		Message: x.GetWarning(key, args...),
+		Key:     key,
+		Args:    args,
+	})
+}
+
+// pushnotetok appends new note by token to the last error.
+func (p *Parser) pushnotetok(tok Tok, key string, args ...any) {
+	// Builtin definitions have not file.
+	if len(p.Errors) == 0 || tok.File == nil {
+		return
+	}
+	log := &p.Errors[len(p.Errors)-1]
+	log.Notes = append(log.Notes, xlog.Note{
+		Row:     tok.Row,
+		Column:  tok.Column,
+		Length:  tok.Length(),
+		Path:    tok.File.Path(),
+		Message: x.GetNote(key, args...),
 	})
 }
 
@@ -118,7 +199,12 @@ func (p *Parser) pusherrs(errs ...xlog.CompilerLog) {
 
 // PushErr appends new error.
 func (p *Parser) PushErr(key string, args ...any) {
-	p.pusherrmsg(x.GetError(key, args...))
+	p.Errors = append(p.Errors, xlog.CompilerLog{
+		Type:    xlog.FlatError,
+		Message: x.GetError(key, args...),
+		Key:     key,
+		Args:    args,
+	})
 }
 
 // pusherrmsh appends new flat error message
@@ -131,9 +217,11 @@ func (p *Parser) pusherrmsg(msg string) {
 
 // pusherr appends new warning.
 func (p *Parser) pushwarn(key string, args ...any) {
-	p.Warnings = append(p.Warnings, xlog.CompilerLog{
+	p.pushwarnlog(xlog.CompilerLog{
 		Type:    xlog.FlatWarning,
 		Message: x.GetWarning(key, args...),
+		Key:     key,
+		Args:    args,
 	})
 }
 
@@ -286,8 +374,10 @@ func cppGlobals(dm *Defmap) string {
 	var cpp strings.Builder
 	for _, g := range dm.Globals {
 		if !g.Const && g.Used && g.Token.Id != tokens.NA {
+			cpp.WriteString(models.LineDirective(g.Token))
 			cpp.WriteString(g.String())
 			cpp.WriteByte('\n')
+			cpp.WriteString(models.GenLineDirective())
 		}
 	}
 	return cpp.String()
@@ -309,8 +399,11 @@ func cppFuncs(dm *Defmap) string {
 	var cpp strings.Builder
 	for _, f := range dm.Funcs {
 		if f.used && f.Ast.Tok.Id != tokens.NA {
+			cpp.WriteString(models.LineDirective(f.Ast.Tok))
 			cpp.WriteString(f.String())
-			cpp.WriteString("\n\n")
+			cpp.WriteByte('\n')
+			cpp.WriteString(models.GenLineDirective())
+			cpp.WriteByte('\n')
 		}
 	}
 	return cpp.String()
@@ -328,6 +421,48 @@ func (p *Parser) CppFuncs() string {
 	return cpp.String()
 }
 
+func (p *Parser) tests() []*function {
+	var tests []*function
+	for _, f := range p.Defs.Funcs {
+		if f.isTest {
+			tests = append(tests, f)
+		}
+	}
+	return tests
+}
+
+// HasTests reports package has test function or not.
+func (p *Parser) HasTests() bool {
+	return len(p.tests()) > 0
+}
+
+// CppTests returns cpp code of test list of package.
+func (p *Parser) CppTests() string {
+	var cpp strings.Builder
+	cpp.WriteString("std::vector<x_test> ")
+	cpp.WriteString(xapi.TestList)
+	cpp.WriteString("(void) {\n")
+	models.AddIndent()
+	indent := models.IndentString()
+	models.DoneIndent()
+	cpp.WriteString(indent)
+	cpp.WriteString("return {")
+	for _, f := range p.tests() {
+		cpp.WriteByte('\n')
+		cpp.WriteString(indent)
+		cpp.WriteString(indent)
+		cpp.WriteString(`{"`)
+		cpp.WriteString(f.Ast.Id)
+		cpp.WriteString(`", `)
+		cpp.WriteString(f.outId())
+		cpp.WriteString("},")
+	}
+	cpp.WriteByte('\n')
+	cpp.WriteString(indent)
+	cpp.WriteString("};\n}")
+	return cpp.String()
+}
+
 // CppInitializerCaller returns cpp code of initializer caller.
 func (p *Parser) CppInitializerCaller() string {
 	var cpp strings.Builder
@@ -373,6 +508,10 @@ func (p *Parser) Cpp() string {
 	cpp.WriteString("\n\n")
 	cpp.WriteString(p.CppFuncs())
 	cpp.WriteString(p.CppInitializerCaller())
+	if p.Test {
+		cpp.WriteString("\n\n")
+		cpp.WriteString(p.CppTests())
+	}
 	return cpp.String()
 }
 
@@ -388,20 +527,19 @@ func (p *Parser) checkCppUsePath(use *models.Use) bool {
 		p.pusherrtok(use.Tok, "invalid_header_ext", ext)
 		return false
 	}
-	err := os.Chdir(use.Tok.File.Dir)
-	if err != nil {
-		p.pusherrtok(use.Tok, "use_not_found", use.Path)
-		return false
+	// Relative paths are relative to directory of source file.
+	path := use.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(use.Tok.File.Dir, path)
 	}
-	info, err := os.Stat(use.Path)
+	info, err := os.Stat(path)
 	// Exist?
 	if err != nil || info.IsDir() {
 		p.pusherrtok(use.Tok, "use_not_found", use.Path)
 		return false
 	}
 	// Set to absolute path for correct include path
-	use.Path, _ = filepath.Abs(use.Path)
-	_ = os.Chdir(x.ExecPath)
+	use.Path = path
 	return true
 }
 
@@ -429,6 +567,29 @@ func (p *Parser) checkUsePath(use *models.Use) bool {
 	for _, puse := range p.Uses {
 		if use.Path == puse.Path {
 			p.pusherrtok(use.Tok, "already_uses")
+			p.pushnotetok(puse.tok, "used_here")
+			return false
+		}
+	}
+	return p.checkUseAlias(use)
+}
+
+// checkUseAlias reports namespace of use declaration is
+// conflicts with alias of another use declaration or not.
+func (p *Parser) checkUseAlias(use *models.Use) bool {
+	if use.Cpp {
+		return true
+	}
+	id := use.Alias
+	if id == "" {
+		id = strings.SplitN(use.LinkString, tokens.DOUBLE_COLON, 2)[0]
+	}
+	for _, puse := range p.Uses {
+		if puse.cppLink || (use.Alias == "" && puse.Alias == "") {
+			continue
+		}
+		if puse.nsIds()[0] == id {
+			p.pusherrtok(use.Tok, "use_alias_exist", id)
 			return false
 		}
 	}
@@ -445,6 +606,7 @@ func (p *Parser) pushSelects(use *use, selectors []Tok) (addNs bool) {
 				break
 			} else if jid.Kind == id.Kind {
 				p.pusherrtok(id, "exist_id", id.Kind)
+				p.pushnotetok(jid, "declared_here")
 				i = -1
 				break
 			}
@@ -458,9 +620,19 @@ func (p *Parser) pushSelects(use *use, selectors []Tok) (addNs bool) {
 		}
 		i, m, t := use.defs.findById(id.Kind, p.File)
 		if i == -1 {
+			// Definitions are accessible for nil file.
+			if i, m, t := use.defs.findById(id.Kind, nil); i != -1 {
+				tok := m.tokOf(i, t)
+				p.pusherrtok(id, "id_not_pub", id.Kind)
+				p.pushnotetok(tok, "defined_here")
+				p.fixNotPub(tok)
+				continue
+			}
 			p.pusherrtok(id, "id_noexist", id.Kind)
+			p.suggest(id.Kind, use.defs.ids("gfesti"))
 			continue
 		}
+		p.pushDefRef(id, m, i, t)
 		switch t {
 		case 'i':
 			p.Defs.side.Traits = append(p.Defs.side.Traits, m.Traits[i])
@@ -493,9 +665,11 @@ func (p *Parser) pushUse(use *use, selectors []Tok) {
 		p.pushDefs(p.Defs.side, use.defs)
 	}
 	ns := new(models.Namespace)
-	ns.Ids = strings.SplitN(use.LinkString, tokens.DOUBLE_COLON, -1)
+	ns.Ids = use.nsIds()
 	src := p.pushNs(ns)
-	src.defs = use.defs
+	// Append instead of assign for keep sub namespaces
+	// of namespace and not modify defines of package.
+	p.pushDefs(src.defs, use.defs)
 }
 
 func (p *Parser) compileCppLinkUse(useAST *models.Use) (*use, bool) {
@@ -517,7 +691,8 @@ func (p *Parser) compilePureUse(useAST *models.Use) (_ *use, hassErr bool) {
 		// Skip directories.
 		if info.IsDir() ||
 			!strings.HasSuffix(name, x.SrcExt) ||
-			!xio.IsUseable(name) {
+			!xio.IsUseable(name) ||
+			xio.IsTest(name) {
 			continue
 		}
 		f, err := xio.Openfx(filepath.Join(useAST.Path, name))
@@ -526,12 +701,14 @@ func (p *Parser) compilePureUse(useAST *models.Use) (_ *use, hassErr bool) {
 			continue
 		}
 		psub := New(f)
+		psub.Refs = p.Refs
 		psub.Parsef(false, false)
 		use := new(use)
 		use.defs = new(Defmap)
 		use.tok = useAST.Tok
 		use.Path = useAST.Path
 		use.LinkString = useAST.LinkString
+		use.Alias = useAST.Alias
 		use.fullUse = useAST.FullUse
 		p.pusherrs(psub.Errors...)
 		p.Warnings = append(p.Warnings, psub.Warnings...)
@@ -567,14 +744,29 @@ func (p *Parser) use(useAST *models.Use) (err bool) {
 		return true
 	}
 	// Already parsed?
-	for _, use := range used {
-		if useAST.Path == use.Path {
-			p.pushUse(use, nil)
-			p.Uses = append(p.Uses, use)
+	for _, puse := range used {
+		if useAST.Path == puse.Path {
+			// Use parsed defines with the selectors of this declaration.
+			cached := new(use)
+			*cached = *puse
+			cached.tok = useAST.Tok
+			cached.LinkString = useAST.LinkString
+			cached.Alias = useAST.Alias
+			cached.fullUse = useAST.FullUse
+			// C++ links have not defines.
+			if !cached.cppLink {
+				p.pushUse(cached, useAST.Selectors)
+			}
+			p.Uses = append(p.Uses, cached)
 			return
 		}
 	}
+	if p.checkUseCycle(useAST) {
+		return true
+	}
+	useChain = append(useChain, useAST)
 	use, err := p.compileUse(useAST)
+	useChain = useChain[:len(useChain)-1]
 	if use == nil {
 		return err
 	}
@@ -583,16 +775,56 @@ func (p *Parser) use(useAST *models.Use) (err bool) {
 	return err
 }
 
+// checkUseCycle reports use declaration creates a cycle
+// with use declarations in compilation or not.
+func (p *Parser) checkUseCycle(useAST *models.Use) bool {
+	if useAST.Cpp {
+		return false
+	}
+	for i, cuse := range useChain {
+		if cuse.Path != useAST.Path {
+			continue
+		}
+		cycle := make([]*models.Use, len(useChain)-i)
+		copy(cycle, useChain[i:])
+		cycle = append(cycle, useAST)
+		var chain strings.Builder
+		for j, cuse := range cycle {
+			if j > 0 {
+				chain.WriteString(" -> ")
+			}
+			chain.WriteString(cuse.LinkString)
+		}
+		// First use is not in cycle, it's entry of cycle.
+		for _, cuse := range cycle[1:] {
+			p.pusherrtok(cuse.Tok, "use_cycle", chain.String())
+		}
+		return true
+	}
+	return false
+}
+
 func (p *Parser) parseUses(tree *[]models.Object) (err bool) {
+	// Comments after last use declaration are kept
+	// because they may be documentation of first definition.
+	start := 0
 	for i, obj := range *tree {
 		switch t := obj.Data.(type) {
 		case models.Use:
 			// || operator used for ignore compiling of other packages
 			// if already have errors
 			err = err || p.use(&t)
+			p.useRow = t.Tok.Row + 1
+			p.headUses = true
+			start = i + 1
 		case models.Comment: // Ignore beginning comments.
+		case models.Preprocessor: // Directives are processed already.
+			start = i + 1
 		default:
-			*tree = (*tree)[i:]
+			if !p.headUses {
+				p.setUseRow((*tree)[:i+1])
+			}
+			*tree = (*tree)[start:]
 			return
 		}
 	}
@@ -624,6 +856,7 @@ func (p *Parser) parseSrcTreeObj(obj models.Object) {
 		p.Comment(t)
 	case models.Use:
 		p.pusherrtok(obj.Tok, "use_at_content")
+		p.fixUseAtContent(obj.Tok)
 	case models.Preprocessor:
 	default:
 		p.pusherrtok(obj.Tok, "invalid_syntax")
@@ -683,6 +916,7 @@ func (p *Parser) useLocalPackage(tree *[]models.Object) (hasErr bool) {
 		if info.IsDir() ||
 			!strings.HasSuffix(name, x.SrcExt) ||
 			!xio.IsUseable(name) ||
+			(!p.Test && xio.IsTest(name)) ||
 			name == p.File.Name {
 			continue
 		}
@@ -695,13 +929,18 @@ func (p *Parser) useLocalPackage(tree *[]models.Object) (hasErr bool) {
 		fp.NoLocalPkg = true
 		fp.NoCheck = true
 		fp.Defs = p.Defs
+		fp.Refs = p.Refs
 		fp.Parsef(false, true)
 		fp.wg.Wait()
 		if len(fp.Errors) > 0 {
 			p.pusherrs(fp.Errors...)
 			return true
 		}
+		p.Warnings = append(p.Warnings, fp.Warnings...)
 		p.waitingGlobals = append(p.waitingGlobals, fp.waitingGlobals...)
+		// Definitions of file are checked by parser of package.
+		p.allows = append(p.allows, fp.allows...)
+		p.pkgCppLinks = append(p.pkgCppLinks, fp.cppLinks...)
 	}
 	return
 }
@@ -711,6 +950,7 @@ func (p *Parser) Parset(tree []models.Object, main, justDefs bool) {
 	p.IsMain = main
 	p.JustDefs = justDefs
 	preprocessor.Process(&tree, !main)
+	p.allows = preprocessor.GetAllows(tree)
 	if !p.parseTree(tree) {
 		return
 	}
@@ -749,7 +989,7 @@ func (p *Parser) checkDoc(obj models.Object) {
 		return
 	}
 	switch obj.Data.(type) {
-	case models.Comment, Attribute, []GenericType:
+	case models.Comment, models.Preprocessor, Attribute, []GenericType:
 		return
 	}
 	p.pushwarntok(obj.Tok, "doc_ignored")
@@ -761,7 +1001,7 @@ func (p *Parser) checkAttribute(obj models.Object) {
 		return
 	}
 	switch obj.Data.(type) {
-	case Attribute, models.Comment, []GenericType:
+	case Attribute, models.Comment, models.Preprocessor, []GenericType:
 		return
 	}
 	p.pusherrtok(obj.Tok, "attribute_not_supports")
@@ -792,6 +1032,7 @@ func (p *Parser) Generics(generics []GenericType) {
 				break
 			} else if generic.Id == cgeneric.Id {
 				p.pusherrtok(generic.Tok, "exist_id", generic.Id)
+				p.pushnotetok(cgeneric.Tok, "declared_here")
 				break
 			}
 		}
@@ -805,6 +1046,7 @@ func (p *Parser) Generics(generics []GenericType) {
 func (p *Parser) Type(t Type) {
 	if _, tok, canshadow := p.defById(t.Id); tok.Id != tokens.NA && !canshadow {
 		p.pusherrtok(t.Tok, "exist_id", t.Id)
+		p.pushnotetok(tok, "declared_here")
 		return
 	} else if xapi.IsIgnoreId(t.Id) {
 		p.pusherrtok(t.Tok, "ignore_id")
@@ -813,6 +1055,7 @@ func (p *Parser) Type(t Type) {
 	t.Desc = p.docText.String()
 	p.docText.Reset()
 	p.Defs.Types = append(p.Defs.Types, &t)
+	p.pushref(t.Tok, SymbolType, &t)
 }
 
 // Enum parses X enumerator statement.
@@ -822,6 +1065,7 @@ func (p *Parser) Enum(e Enum) {
 		return
 	} else if _, tok, _ := p.defById(e.Id); tok.Id != tokens.NA {
 		p.pusherrtok(e.Tok, "exist_id", e.Id)
+		p.pushnotetok(tok, "declared_here")
 		return
 	}
 	e.Desc = p.docText.String()
@@ -842,6 +1086,7 @@ func (p *Parser) Enum(e Enum) {
 		p.Uses = uses
 		p.Defs.Enums = append(p.Defs.Enums, &e)
 	}()
+	p.pushref(e.Tok, SymbolEnum, &e)
 	max := xtype.MaxOfType(e.Type.Id)
 	for i, item := range e.Items {
 		if max == 0 {
@@ -849,6 +1094,7 @@ func (p *Parser) Enum(e Enum) {
 		} else {
 			max--
 		}
+		p.pushref(item.Tok, SymbolEnumItem, enumItem{&e, item})
 		if xapi.IsIgnoreId(item.Id) {
 			p.pusherrtok(item.Tok, "ignore_id")
 		} else {
@@ -858,6 +1104,7 @@ func (p *Parser) Enum(e Enum) {
 				}
 				if item.Id == checkItem.Id {
 					p.pusherrtok(item.Tok, "exist_id", item.Id)
+					p.pushnotetok(checkItem.Tok, "declared_here")
 					break
 				}
 			}
@@ -890,6 +1137,7 @@ func (p *Parser) pushField(s *xstruct, f *Var, i int) {
 		}
 		if f.Id == cf.Id {
 			p.pusherrtok(f.Token, "exist_id", f.Id)
+			p.pushnotetok(cf.Token, "declared_here")
 			break
 		}
 	}
@@ -922,6 +1170,7 @@ func (p *Parser) parseFields(s *xstruct) {
 	s.Defs.Globals = make([]*models.Var, len(s.Ast.Fields))
 	for i, f := range s.Ast.Fields {
 		s.Defs.Globals[i] = f
+		p.pushref(f.Token, SymbolField, f)
 		p.pushField(s, f, i)
 	}
 }
@@ -933,6 +1182,7 @@ func (p *Parser) Struct(s Struct) {
 		return
 	} else if _, tok, _ := p.defById(s.Id); tok.Id != tokens.NA {
 		p.pusherrtok(s.Tok, "exist_id", s.Id)
+		p.pushnotetok(tok, "declared_here")
 		return
 	}
 	xs := new(xstruct)
@@ -944,6 +1194,7 @@ func (p *Parser) Struct(s Struct) {
 	xs.Ast.Generics = p.generics
 	p.generics = nil
 	xs.Defs = new(Defmap)
+	p.pushref(s.Tok, SymbolStruct, xs)
 	p.parseFields(xs)
 }
 
@@ -952,14 +1203,16 @@ func (p *Parser) CppLink(link models.CppLink) {
 	if xapi.IsIgnoreId(link.Link.Id) {
 		p.pusherrtok(link.Tok, "ignore_id")
 		return
-	} else if p.linkById(link.Link.Id) != nil {
+	} else if def := p.linkById(link.Link.Id); def != nil {
 		p.pusherrtok(link.Tok, "exist_id", link.Link.Id)
+		p.pushnotetok(def.Tok, "declared_here")
 		return
 	}
 	linkf := link.Link
 	setGenerics(linkf, p.generics)
 	p.generics = nil
 	p.cppLinks = append(p.cppLinks, &link)
+	p.pushref(link.Link.Tok, SymbolCppLink, &link)
 }
 
 // Trait parses X trait.
@@ -969,6 +1222,7 @@ func (p *Parser) Trait(t models.Trait) {
 		return
 	} else if _, tok, _ := p.defById(t.Id); tok.Id != tokens.NA {
 		p.pusherrtok(t.Tok, "exist_id", t.Id)
+		p.pushnotetok(tok, "declared_here")
 		return
 	}
 	trait := new(trait)
@@ -986,6 +1240,7 @@ func (p *Parser) Trait(t models.Trait) {
 				break
 			} else if f.Id == jf.Id {
 				p.pusherrtok(f.Tok, "exist_id", f.Id)
+				p.pushnotetok(jf.Tok, "declared_here")
 			}
 		}
 		_ = p.checkParamDup(f.Params)
@@ -993,23 +1248,29 @@ func (p *Parser) Trait(t models.Trait) {
 		tf := new(function)
 		tf.Ast = f
 		trait.Defs.Funcs[i] = tf
+		p.pushref(f.Tok, SymbolMethod, tf)
 	}
 	p.Defs.Traits = append(p.Defs.Traits, trait)
+	p.pushref(t.Tok, SymbolTrait, trait)
 }
 
 func (p *Parser) implTrait(impl models.Impl) {
 	trait, _, _ := p.traitById(impl.Trait.Kind)
 	if trait == nil {
 		p.pusherrtok(impl.Trait, "id_noexist", impl.Trait.Kind)
+		p.suggest(impl.Trait.Kind, p.scopeIds("i"))
 		return
 	}
 	trait.Used = true
+	p.pushref(impl.Trait, SymbolTrait, trait)
 	sid, _ := impl.Target.KindId()
 	xs, _, _ := p.Defs.structById(sid, nil)
 	if xs == nil {
 		p.pusherrtok(impl.Target.Tok, "id_noexist", sid)
+		p.suggest(sid, p.Defs.ids("s"))
 		return
 	}
+	p.pushref(impl.Target.Tok, SymbolStruct, xs)
 	impl.Target.Tag = xs
 	xs.traits = append(xs.traits, trait)
 	for _, tf := range trait.Defs.Funcs {
@@ -1026,6 +1287,7 @@ func (p *Parser) implTrait(impl models.Impl) {
 		}
 		if !ok {
 			p.pusherrtok(impl.Target.Tok, "notimpl_trait_def", trait.Ast.Id, ds)
+			p.pushnotetok(tf.Ast.Tok, "trait_def_here")
 		}
 	}
 	for _, obj := range impl.Tree {
@@ -1035,13 +1297,16 @@ func (p *Parser) implTrait(impl models.Impl) {
 		case models.Comment:
 			p.Comment(t)
 		case *Func:
-			if trait.FindFunc(t.Id) == nil {
+			tf := trait.FindFunc(t.Id)
+			if tf == nil {
 				p.pusherrtok(impl.Target.Tok, "trait_hasnt_id", trait.Ast.Id, t.Id)
+				p.suggest(t.Id, trait.Defs.ids("f"))
 				break
 			}
-			i, _, _ := xs.Defs.findById(t.Id, nil)
+			i, m, code := xs.Defs.findById(t.Id, nil)
 			if i != -1 {
 				p.pusherrtok(t.Tok, "exist_id", t.Id)
+				p.pushnotetok(m.tokOf(i, code), "declared_here")
 				continue
 			}
 			sf := new(function)
@@ -1058,6 +1323,8 @@ func (p *Parser) implTrait(impl models.Impl) {
 				p.parseTypesNonGenerics(sf.Ast)
 			}
 			xs.Defs.Funcs = append(xs.Defs.Funcs, sf)
+			p.pushref(t.Tok, SymbolMethod, sf)
+			p.Refs.link(t.Tok, tf.Ast.Tok)
 		}
 	}
 }
@@ -1066,8 +1333,10 @@ func (p *Parser) implStruct(impl models.Impl) {
 	xs, _, _ := p.Defs.structById(impl.Trait.Kind, nil)
 	if xs == nil {
 		p.pusherrtok(impl.Trait, "id_noexist", impl.Trait.Kind)
+		p.suggest(impl.Trait.Kind, p.Defs.ids("s"))
 		return
 	}
+	p.pushref(impl.Trait, SymbolStruct, xs)
 	for _, obj := range impl.Tree {
 		switch t := obj.Data.(type) {
 		case models.Attribute:
@@ -1077,9 +1346,10 @@ func (p *Parser) implStruct(impl models.Impl) {
 		case models.Comment:
 			p.Comment(t)
 		case *Func:
-			i, _, _ := xs.Defs.findById(t.Id, nil)
+			i, m, code := xs.Defs.findById(t.Id, nil)
 			if i != -1 {
 				p.pusherrtok(t.Tok, "exist_id", t.Id)
+				p.pushnotetok(m.tokOf(i, code), "declared_here")
 				continue
 			}
 			sf := new(function)
@@ -1094,14 +1364,16 @@ func (p *Parser) implStruct(impl models.Impl) {
 			setGenerics(sf.Ast, p.generics)
 			p.generics = nil
 			for _, generic := range t.Generics {
-				if findGeneric(generic.Id, xs.Ast.Generics) != nil {
+				if sg := findGeneric(generic.Id, xs.Ast.Generics); sg != nil {
 					p.pusherrtok(generic.Tok, "exist_id", generic.Id)
+					p.pushnotetok(sg.Tok, "declared_here")
 				}
 			}
 			if len(xs.Ast.Generics) == 0 {
 				p.parseTypesNonGenerics(sf.Ast)
 			}
 			xs.Defs.Funcs = append(xs.Defs.Funcs, sf)
+			p.pushref(t.Tok, SymbolMethod, sf)
 		}
 	}
 }
@@ -1223,6 +1495,7 @@ func (p *Parser) parseCommonNonGenericType(generics []*GenericType, t *DataType)
 		def, _, _ := p.defById(id)
 		switch deft := def.(type) {
 		case *xstruct:
+			p.pushTypeRef(t.Tok, id, SymbolStruct, deft)
 			deft = p.structConstructorInstance(deft)
 			if t.Tag != nil {
 				deft.SetGenerics(t.Tag.([]DataType))
@@ -1360,13 +1633,17 @@ func (p *Parser) checkRetVars(f *function) {
 		if xapi.IsIgnoreId(v.Kind) {
 			continue
 		}
+		// Token of previous declaration.
+		var prev Tok
 		for _, generic := range f.Ast.Generics {
 			if v.Kind == generic.Id {
+				prev = generic.Tok
 				goto exist
 			}
 		}
 		for _, param := range f.Ast.Params {
 			if v.Kind == param.Id {
+				prev = param.Tok
 				goto exist
 			}
 		}
@@ -1375,12 +1652,14 @@ func (p *Parser) checkRetVars(f *function) {
 				break
 			}
 			if jv.Kind == v.Kind {
+				prev = jv
 				goto exist
 			}
 		}
 		continue
 	exist:
 		p.pusherrtok(v, "exist_id", v.Kind)
+		p.pushnotetok(prev, "declared_here")
 
 	}
 }
@@ -1397,6 +1676,7 @@ func (p *Parser) Func(fast Func) {
 	_, tok, canshadow := p.defById(fast.Id)
 	if tok.Id != tokens.NA && !canshadow {
 		p.pusherrtok(fast.Tok, "exist_id", fast.Id)
+		p.pushnotetok(tok, "declared_here")
 	} else if xapi.IsIgnoreId(fast.Id) {
 		p.pusherrtok(fast.Tok, "ignore_id")
 	}
@@ -1415,18 +1695,21 @@ func (p *Parser) Func(fast Func) {
 	f.used = f.Ast.Id == x.InitializerFunction
 	p.parseTypesNonGenerics(f.Ast)
 	p.Defs.Funcs = append(p.Defs.Funcs, f)
+	p.pushref(f.Ast.Tok, SymbolFunc, f)
 }
 
 // ParseVariable parse X global variable.
 func (p *Parser) Global(vast Var) {
-	def, _, _ := p.defById(vast.Id)
+	def, tok, _ := p.defById(vast.Id)
 	if def != nil {
 		p.pusherrtok(vast.Token, "exist_id", vast.Id)
+		p.pushnotetok(tok, "declared_here")
 		return
 	} else {
 		for _, g := range p.waitingGlobals {
 			if vast.Id == g.Var.Id {
 				p.pusherrtok(vast.Token, "exist_id", vast.Id)
+				p.pushnotetok(g.Var.Token, "declared_here")
 				return
 			}
 		}
@@ -1438,6 +1721,7 @@ func (p *Parser) Global(vast Var) {
 	wg := waitingGlobal{Var: v, Defs: p.Defs}
 	p.waitingGlobals = append(p.waitingGlobals, wg)
 	p.Defs.Globals = append(p.Defs.Globals, v)
+	p.pushref(v.Token, SymbolGlobal, v)
 }
 
 // Var parse X variable.
@@ -1521,12 +1805,27 @@ func (p *Parser) checkTypeParam(f *function) {
 	}
 }
 
+func (p *Parser) checkTestFunc(f *function) {
+	if len(f.Ast.Generics) != 0 {
+		p.pusherrtok(f.Ast.Tok, "func_cant_have_generics_if_has_attribute", x.Attribute_Test)
+	}
+	if len(f.Ast.Params) != 0 {
+		p.pusherrtok(f.Ast.Tok, "func_cant_have_params_if_has_attribute", x.Attribute_Test)
+	}
+	if f.Ast.RetType.Type.Id != xtype.Void {
+		p.pusherrtok(f.Ast.RetType.Type.Tok, "func_cant_have_ret_if_has_attribute", x.Attribute_Test)
+	}
+	f.isTest = true
+}
+
 func (p *Parser) checkFuncAttributes(f *function) {
 	for _, attribute := range f.Ast.Attributes {
 		switch attribute.Tag.Kind {
 		case x.Attribute_Inline:
 		case x.Attribute_TypeArg:
 			p.checkTypeParam(f)
+		case x.Attribute_Test:
+			p.checkTestFunc(f)
 		default:
 			p.pusherrtok(attribute.Tok, "invalid_attribute")
 		}
@@ -1553,6 +1852,7 @@ func (p *Parser) varsFromParams(params []Param) []*Var {
 			v.Type.ComponentType = &param.Type
 		}
 		vars[i] = v
+		p.pushref(v.Token, SymbolVar, v)
 	}
 	return vars
 }
@@ -1707,8 +2007,18 @@ func (p *Parser) blockDefById(id string) (def any, tok Tok) {
 	return
 }
 
+// recoverCheck reports panic of checks as error.
+// Checks are runs in goroutine and may panic for broken code such as
+// code in editing, panic is not recoverable out of goroutine.
+func (p *Parser) recoverCheck() {
+	if err := recover(); err != nil {
+		p.PushErr("compiler_crashed", err)
+	}
+}
+
 func (p *Parser) check() {
 	defer p.wg.Done()
+	defer p.recoverCheck()
 	if p.IsMain && !p.JustDefs {
 		f, _, _ := p.Defs.funcById(x.EntryPoint, nil)
 		if f == nil {
@@ -1718,6 +2028,11 @@ func (p *Parser) check() {
 			f.used = true
 		}
 	}
+	if p.Test {
+		for _, f := range p.tests() {
+			f.used = true
+		}
+	}
 	p.checkTypes()
 	p.WaitingGlobals()
 	p.waitingGlobals = nil
@@ -1796,6 +2111,7 @@ func (p *Parser) checkParamDup(params []models.Param) (err bool) {
 			} else if param.Id == jparam.Id {
 				err = true
 				p.pusherrtok(param.Tok, "exist_id", param.Id)
+				p.pushnotetok(jparam.Tok, "declared_here")
 			}
 		}
 	}
@@ -1821,7 +2137,10 @@ func (p *Parser) params(f *Func) (err bool) {
 
 func (p *Parser) blockVarsOfFunc(f *Func) []*Var {
 	vars := p.varsFromParams(f.Params)
-	vars = append(vars, f.RetType.Vars()...)
+	for _, v := range f.RetType.Vars() {
+		vars = append(vars, v)
+		p.pushref(v.Token, SymbolVar, v)
+	}
 	if f.Receiver != nil {
 		s := f.Receiver.Tag.(*xstruct)
 		vars = append(vars, s.selfVar(*f.Receiver))
@@ -2238,6 +2557,10 @@ func (p *Parser) parseFuncCallToks(f *Func, genericsToks, argsToks Toks, m *expr
 			return
 		}
 		args = p.getArgs(argsToks, false)
+		if args == nil {
+			p.eval.hasError = true
+			return
+		}
 		args.Generics = generics
 	}
 	return p.parseFuncCall(f, args, m, argsToks[0])
@@ -2444,11 +2767,13 @@ func (p *Parser) checkNewBlockCustom(b *models.Block, oldBlockVars []*Var) {
 	for _, v := range vars {
 		if !v.Used {
 			p.pusherrtok(v.Token, "declared_but_not_used", v.Id)
+			p.fixUnusedVar(v)
 		}
 	}
 	for _, t := range types {
 		if !t.Used {
 			p.pusherrtok(t.Tok, "declared_but_not_used", t.Id)
+			p.fixUnusedLine(t.Tok, tokens.TYPE+" ")
 		}
 	}
 	p.blockVars = oldBlockVars
@@ -2479,8 +2804,9 @@ func (p *Parser) statement(s *models.Statement, recover bool) bool {
 	case models.Continue:
 		p.continueStatement(&t)
 	case Type:
-		if def, _ := p.blockDefById(t.Id); def != nil {
+		if def, tok := p.blockDefById(t.Id); def != nil {
 			p.pusherrtok(t.Tok, "exist_id", t.Id)
+			p.pushnotetok(tok, "declared_here")
 			break
 		} else if xapi.IsIgnoreId(t.Id) {
 			p.pusherrtok(t.Tok, "ignore_id")
@@ -2488,6 +2814,7 @@ func (p *Parser) statement(s *models.Statement, recover bool) bool {
 		}
 		t.Type, _ = p.realType(t.Type, true)
 		p.blockTypes = append(p.blockTypes, &t)
+		p.pushref(t.Tok, SymbolType, &t)
 	case *models.Block:
 		p.checkNewBlock(t)
 		s.Data = t
@@ -2551,8 +2878,10 @@ func (p *Parser) checkStatement(b *models.Block, i *int) {
 
 func (p *Parser) checkBlock(b *models.Block) {
 	for i := 0; i < len(b.Tree); i++ {
+		p.completion.capture(p, b.Tree[i].Tok)
 		p.checkStatement(b, &i)
 	}
+	p.completion.captureEnd(p, b)
 }
 
 func (p *Parser) recoverFuncExprStatement(s *models.ExprStatement) {
@@ -2676,10 +3005,12 @@ func (p *Parser) checkLabels() {
 				break
 			} else if label.Label == checkLabel.Label {
 				p.pusherrtok(label.Tok, "label_exist", label.Label)
+				p.pushnotetok(checkLabel.Tok, "declared_here")
 			}
 		}
 		if !label.Used {
 			p.pusherrtok(label.Tok, "declared_but_not_used", label.Label+":")
+			p.fixUnusedLine(label.Tok, label.Label+tokens.COLON)
 		}
 	}
 }
@@ -2812,6 +3143,7 @@ func (p *Parser) checkRets(f *Func) {
 	}
 	if !typeIsVoid(f.RetType.Type) {
 		p.pusherrtok(f.Tok, "missing_ret")
+		p.fixMissingRet(f)
 	}
 }
 
@@ -2835,12 +3167,14 @@ always:
 func (p *Parser) varStatement(v *Var, noParse bool) {
 	if _, tok := p.blockDefById(v.Id); tok.Id != tokens.NA {
 		p.pusherrtok(v.Token, "exist_id", v.Id)
+		p.pushnotetok(tok, "declared_here")
 	}
 	if !noParse {
 		*v = *p.Var(*v)
 	}
 	v.IsField = true
 	p.blockVars = append(p.blockVars, v)
+	p.pushref(v.Token, SymbolVar, v)
 }
 
 func (p *Parser) deferredCall(d *models.Defer) {
@@ -3373,7 +3707,9 @@ func (p *Parser) typeSource(dt DataType, err bool) (ret DataType, ok bool) {
 			if defs == nil {
 				return
 			}
-			i, m, t := defs.findById(toks[0].Kind, p.File)
+			// Token of data type is the identifier after namespaces.
+			id = toks[0].Kind
+			i, m, t := defs.findById(id, p.File)
 			switch t {
 			case 't':
 				def = m.Types[i]
@@ -3390,12 +3726,15 @@ func (p *Parser) typeSource(dt DataType, err bool) (ret DataType, ok bool) {
 		switch t := def.(type) {
 		case *Type:
 			t.Used = true
+			p.pushTypeRef(dt.Tok, id, SymbolType, t)
 			return p.typeSourceIsType(dt, t, err)
 		case *Enum:
 			t.Used = true
+			p.pushTypeRef(dt.Tok, id, SymbolEnum, t)
 			return p.typeSourceIsEnum(t, dt.Tag)
 		case *xstruct:
 			t.Used = true
+			p.pushTypeRef(dt.Tok, id, SymbolStruct, t)
 			t = p.structConstructorInstance(t)
 			switch tagt := dt.Tag.(type) {
 			case []models.DataType:
@@ -3404,6 +3743,7 @@ func (p *Parser) typeSource(dt DataType, err bool) (ret DataType, ok bool) {
 			return p.typeSourceIsStruct(t, dt)
 		case *trait:
 			t.Used = true
+			p.pushTypeRef(dt.Tok, id, SymbolTrait, t)
 			return p.typeSourceIsTrait(t, dt.Tag, dt.Tok)
 		default:
 			if err {
@@ -0,0 +1,236 @@
+package parser
+
+import (
+	"sync"
+
+	"github.com/the-xlang/xxc/ast/models"
+	"github.com/the-xlang/xxc/lex/tokens"
+)
+
+// Kinds of symbols.
+const (
+	SymbolVar      = "var"
+	SymbolGlobal   = "global"
+	SymbolField    = "field"
+	SymbolFunc     = "func"
+	SymbolMethod   = "method"
+	SymbolCppLink  = "cpp link"
+	SymbolStruct   = "struct"
+	SymbolTrait    = "trait"
+	SymbolEnum     = "enum"
+	SymbolEnumItem = "enum item"
+	SymbolType     = "type"
+	SymbolNs       = "namespace"
+)
+
+// Symbol is a definition referred by identifiers.
+type Symbol struct {
+	Kind string
+	Id   string
+	// Identifier token of definition, file is nil for built-in definitions.
+	Tok Tok
+	// Data type of variables, signature of functions
+	// and source type of enums and type aliases.
+	Type string
+	Desc string
+}
+
+// String returns declaration of symbol without body.
+func (s Symbol) String() string {
+	switch s.Kind {
+	case SymbolVar, SymbolGlobal, SymbolField:
+		return s.Id + ": " + s.Type
+	case SymbolFunc, SymbolMethod, SymbolCppLink:
+		return s.Id + s.Type
+	case SymbolEnum:
+		return "enum " + s.Id + ": " + s.Type
+	case SymbolEnumItem:
+		return s.Type + "." + s.Id
+	case SymbolType:
+		return "type " + s.Id + " " + s.Type
+	default:
+		return s.Kind + " " + s.Id
+	}
+}
+
+// Ref is a reference of identifier to definition.
+// Definitions are refers to themselves.
+type Ref struct {
+	Tok  Tok
+	kind string
+	def  any
+}
+
+type enumItem struct {
+	enum *Enum
+	item *models.EnumItem
+}
+
+// Symbol returns symbol of definition of reference.
+func (r *Ref) Symbol() Symbol { return symbolOf(r.kind, r.def) }
+
+// symbolOf returns symbol of definition.
+func symbolOf(kind string, def any) Symbol {
+	s := Symbol{Kind: kind}
+	switch t := def.(type) {
+	case *Var:
+		s.Id, s.Tok, s.Type, s.Desc = t.Id, t.Token, t.Type.Kind, t.Desc
+	case *function:
+		s.Id, s.Tok, s.Type, s.Desc = t.Ast.Id, t.Ast.Tok, t.Ast.DataTypeString(), t.Desc
+	case *models.CppLink:
+		s.Id, s.Tok, s.Type = t.Link.Id, t.Link.Tok, t.Link.DataTypeString()
+	case *xstruct:
+		s.Id, s.Tok, s.Desc = t.Ast.Id, t.Ast.Tok, t.Desc
+	case *trait:
+		s.Id, s.Tok, s.Desc = t.Ast.Id, t.Ast.Tok, t.Desc
+	case *Enum:
+		s.Id, s.Tok, s.Type, s.Desc = t.Id, t.Tok, t.Type.Kind, t.Desc
+	case *Type:
+		s.Id, s.Tok, s.Type, s.Desc = t.Id, t.Tok, t.Type.Kind, t.Desc
+	case enumItem:
+		s.Id, s.Tok, s.Type = t.item.Id, t.item.Tok, t.enum.Id
+	case *namespace:
+		s.Id, s.Tok = t.Id, t.Tok
+	}
+	return s
+}
+
+type refPos struct {
+	path   string
+	row    int
+	column int
+}
+
+// Link is a pair of definitions which must have same identifier,
+// such as functions of traits and their implementations.
+type Link [2]Tok
+
+// Refs are the references of identifiers of compilation.
+// Parser records references if it has refs.
+type Refs struct {
+	mu    sync.Mutex
+	list  []Ref
+	pos   map[refPos]int
+	links []Link
+}
+
+// push records reference of token if it is not recorded already.
+// Tokens without file are ignored.
+func (r *Refs) push(tok Tok, kind string, def any) {
+	if r == nil || tok.File == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pos := refPos{tok.File.Path(), tok.Row, tok.Column}
+	if r.pos == nil {
+		r.pos = map[refPos]int{}
+	} else if _, ok := r.pos[pos]; ok {
+		return
+	}
+	r.pos[pos] = len(r.list)
+	r.list = append(r.list, Ref{Tok: tok, kind: kind, def: def})
+}
+
+// At returns reference at position of file.
+// Returns nil if there is no reference.
+func (r *Refs) At(path string, row, column int) *Ref {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.list {
+		ref := &r.list[i]
+		if ref.Tok.Row == row &&
+			column >= ref.Tok.Column && column < ref.Tok.Column+ref.Tok.Length() &&
+			ref.Tok.File.Path() == path {
+			return ref
+		}
+	}
+	return nil
+}
+
+// List returns references by order of recording.
+func (r *Refs) List() []Ref {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Ref(nil), r.list...)
+}
+
+// link records link of definitions.
+func (r *Refs) link(a, b Tok) {
+	if r == nil || a.File == nil || b.File == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.links = append(r.links, Link{a, b})
+}
+
+// Links returns links of definitions by order of recording.
+func (r *Refs) Links() []Link {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Link(nil), r.links...)
+}
+
+// pushref records reference if references are recorded.
+func (p *Parser) pushref(tok Tok, kind string, def any) {
+	if tok.Id == tokens.Self {
+		return
+	}
+	p.Refs.push(tok, kind, def)
+}
+
+// defOf returns kind and definition of findById result.
+func defOf(m *Defmap, i int, code byte) (string, any) {
+	switch code {
+	case 'g':
+		return SymbolGlobal, m.Globals[i]
+	case 'f':
+		return SymbolFunc, m.Funcs[i]
+	case 'e':
+		return SymbolEnum, m.Enums[i]
+	case 's':
+		return SymbolStruct, m.Structs[i]
+	case 't':
+		return SymbolType, m.Types[i]
+	case 'i':
+		return SymbolTrait, m.Traits[i]
+	}
+	return "", nil
+}
+
+// pushDefRef records reference to definition of findById result.
+func (p *Parser) pushDefRef(tok Tok, m *Defmap, i int, code byte) {
+	if kind, def := defOf(m, i, code); def != nil {
+		p.pushref(tok, kind, def)
+	}
+}
+
+// pushTypeRef records reference of identifier of data type.
+// Token of data type is may be a prefix such as pointer or slice,
+// reference is not recorded for them.
+func (p *Parser) pushTypeRef(tok Tok, id, kind string, def any) {
+	if tok.Kind == id {
+		p.pushref(tok, kind, def)
+	}
+}
+
+// ScopeSymbol returns symbol of definition which is visible by id
+// out of blocks of package, such as globals, functions, used
+// namespaces, cpp links and built-in definitions.
+// Returns false if there is no such definition.
+func (p *Parser) ScopeSymbol(id string) (Symbol, bool) {
+	if ns := p.nsById(id); ns != nil {
+		return symbolOf(SymbolNs, ns), true
+	}
+	if link := p.linkById(id); link != nil {
+		return symbolOf(SymbolCppLink, link), true
+	}
+	if i, m, code := p.Defs.findById(id, p.File); i != -1 {
+		return symbolOf(defOf(m, i, code)), true
+	}
+	if i, m, code := Builtin.findById(id, nil); i != -1 {
+		return symbolOf(defOf(m, i, code)), true
+	}
+	return Symbol{}, false
+}
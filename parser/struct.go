@@ -179,9 +179,12 @@ func (s *xstruct) decldefString() string {
 	cpp.WriteString("(void) noexcept {}\n\n")
 	for _, f := range s.Defs.Funcs {
 		if f.used {
+			cpp.WriteString(models.LineDirective(f.Ast.Tok))
 			cpp.WriteString(models.IndentString())
 			cpp.WriteString(f.String())
-			cpp.WriteString("\n\n")
+			cpp.WriteByte('\n')
+			cpp.WriteString(models.GenLineDirective())
+			cpp.WriteByte('\n')
 		}
 	}
 	cpp.WriteString(s.operators())
@@ -47,6 +47,17 @@ func (sap *structArgParser) buildArgs() {
 	}
 }
 
+// fieldIds returns identifiers of accessible fields.
+func (sap *structArgParser) fieldIds() []string {
+	var ids []string
+	for _, param := range sap.f.Params {
+		if _, ok := (*sap.fmap)[param.Id]; ok {
+			ids = append(ids, param.Id)
+		}
+	}
+	return ids
+}
+
 func (sap *structArgParser) pushArg() {
 	defer func() { sap.i++ }()
 	if sap.arg.TargetId == "" {
@@ -56,6 +67,7 @@ func (sap *structArgParser) pushArg() {
 	pair, ok := (*sap.fmap)[sap.arg.TargetId]
 	if !ok {
 		sap.p.pusherrtok(sap.arg.Tok, "id_noexist", sap.arg.TargetId)
+		sap.p.suggest(sap.arg.TargetId, sap.fieldIds())
 		return
 	} else if pair.arg != nil {
 		sap.p.pusherrtok(sap.arg.Tok, "already_has_expr", sap.arg.TargetId)
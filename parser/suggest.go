@@ -0,0 +1,211 @@
+package parser
+
+import (
+	"github.com/the-xlang/xxc/pkg/x"
+	"github.com/the-xlang/xxc/pkg/xapi"
+	"github.com/the-xlang/xxc/pkg/xlog"
+)
+
+// editDistance returns optimal string alignment distance of a and b.
+// It is Levenshtein distance which counts transposition of
+// adjacent runes as one edit too, such as lne for len.
+func editDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	// Rows of distances of previous two and current prefixes of a.
+	prev2 := make([]int, len(br)+1)
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j-1] + cost
+			if prev[j]+1 < curr[j] {
+				curr[j] = prev[j] + 1
+			}
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+			if i > 1 && j > 1 && ar[i-1] == br[j-2] && ar[i-2] == br[j-1] &&
+				prev2[j-2]+1 < curr[j] {
+				curr[j] = prev2[j-2] + 1
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(br)]
+}
+
+// isAbbrev reports id is an abbreviation of cid.
+// Abbreviations start with first rune of cid and
+// have runes of cid in order, such as clr for clear.
+func isAbbrev(id, cid string) bool {
+	ir, cr := []rune(id), []rune(cid)
+	if len(ir) >= len(cr) || ir[0] != cr[0] {
+		return false
+	}
+	i := 0
+	for _, r := range cr {
+		if i < len(ir) && ir[i] == r {
+			i++
+		}
+	}
+	return i == len(ir)
+}
+
+// closestId returns closest identifier to id by edit distance.
+// Identifier which id is abbreviation of it is closest
+// if there is no close enough identifier by edit distance.
+// Returns empty string if there is no close identifier.
+// First one is returned if there are many closest identifiers.
+func closestId(id string, ids []string) string {
+	// Maximum distance is one third of identifier, at least one.
+	max := len([]rune(id)) / 3
+	if max < 1 {
+		max = 1
+	}
+	closest, abbrev := "", ""
+	min := max + 1
+	for _, cid := range ids {
+		if cid == id || cid == "" || xapi.IsIgnoreId(cid) {
+			continue
+		}
+		if d := editDistance(id, cid); d < min {
+			closest = cid
+			min = d
+		} else if isAbbrev(id, cid) && (abbrev == "" || len(cid) < len(abbrev)) {
+			abbrev = cid
+		}
+	}
+	if closest == "" {
+		return abbrev
+	}
+	return closest
+}
+
+// ids returns identifiers of definitions by types of findById.
+// For example, "sf" is returns identifiers of structures and functions.
+func (dm *Defmap) ids(types string) []string {
+	var ids []string
+	for _, t := range []byte(types) {
+		switch t {
+		case 'g':
+			for _, g := range dm.Globals {
+				if g != nil {
+					ids = append(ids, g.Id)
+				}
+			}
+		case 'f':
+			for _, f := range dm.Funcs {
+				if f != nil {
+					ids = append(ids, f.Ast.Id)
+				}
+			}
+		case 'e':
+			for _, e := range dm.Enums {
+				if e != nil {
+					ids = append(ids, e.Id)
+				}
+			}
+		case 's':
+			for _, s := range dm.Structs {
+				if s != nil {
+					ids = append(ids, s.Ast.Id)
+				}
+			}
+		case 't':
+			for _, t := range dm.Types {
+				if t != nil {
+					ids = append(ids, t.Id)
+				}
+			}
+		case 'i':
+			for _, t := range dm.Traits {
+				if t != nil {
+					ids = append(ids, t.Ast.Id)
+				}
+			}
+		}
+	}
+	if dm.side != nil {
+		ids = append(ids, dm.side.ids(types)...)
+	}
+	return ids
+}
+
+// nsIds returns identifiers of namespaces.
+func (dm *Defmap) nsIds() []string {
+	ids := make([]string, 0, len(dm.Namespaces))
+	for _, ns := range dm.Namespaces {
+		if ns != nil {
+			ids = append(ids, ns.Id)
+		}
+	}
+	return ids
+}
+
+func (p *Parser) nsIds() []string { return p.Defs.nsIds() }
+
+// scopeIds returns identifiers of visible definitions by types of findById.
+// Block variables and block types are included always.
+func (p *Parser) scopeIds(types string) []string {
+	var ids []string
+	// Last declared block definitions are closest to usage.
+	for i := len(p.blockVars) - 1; i >= 0; i-- {
+		if v := p.blockVars[i]; v != nil {
+			ids = append(ids, v.Id)
+		}
+	}
+	for i := len(p.blockTypes) - 1; i >= 0; i-- {
+		if t := p.blockTypes[i]; t != nil {
+			ids = append(ids, t.Id)
+		}
+	}
+	ids = append(ids, p.Defs.ids(types)...)
+	if p.allowBuiltin {
+		ids = append(ids, Builtin.ids(types)...)
+	}
+	return ids
+}
+
+// linkIds returns identifiers of cpp links.
+func (p *Parser) linkIds() []string {
+	ids := make([]string, len(p.cppLinks))
+	for i, link := range p.cppLinks {
+		ids[i] = link.Link.Id
+	}
+	return ids
+}
+
+// pushnote appends new note without location to the last error.
+func (p *Parser) pushnote(key string, args ...any) {
+	if len(p.Errors) == 0 {
+		return
+	}
+	log := &p.Errors[len(p.Errors)-1]
+	log.Notes = append(log.Notes, xlog.Note{Message: x.GetNote(key, args...)})
+}
+
+// suggest appends suggestion to the last error
+// if there is a close identifier to id.
+func (p *Parser) suggest(id string, ids []string) {
+	if closest := closestId(id, ids); closest != "" {
+		p.pushnote("did_you_mean", closest)
+	}
+}
+
+// pushnoexist appends error of not exist identifier of token
+// with suggestion of closest identifier.
+func (e *eval) pushnoexist(tok Tok, key string, ids []string) {
+	if e.hasError {
+		return
+	}
+	e.pusherrtok(tok, key, tok.Kind)
+	e.p.suggest(tok.Kind, ids)
+}
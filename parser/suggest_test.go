@@ -0,0 +1,56 @@
+package parser
+
+import "testing"
+
+func TestEditDistance(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"len", "len", 0},
+		{"", "len", 3},
+		{"lne", "len", 1},
+		{"lenght", "length", 1},
+		{"fnid", "find", 1},
+		{"ab", "ba", 1},
+		{"ca", "abc", 3},
+		{"clr", "clear", 2},
+		{"kitten", "sitting", 3},
+		{"çiçek", "çicek", 1},
+	}
+	for _, c := range cases {
+		if got := editDistance(c.a, c.b); got != c.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+		if got := editDistance(c.b, c.a); got != c.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", c.b, c.a, got, c.want)
+		}
+	}
+}
+
+func TestClosestId(t *testing.T) {
+	members := []string{"len", "find", "rfind", "clear", "has_prefix", "replace"}
+	cases := []struct {
+		id   string
+		ids  []string
+		want string
+	}{
+		{"lne", members, "len"},
+		{"fnid", members, "find"},
+		{"clr", members, "clear"},
+		{"has_prefx", members, "has_prefix"},
+		{"repalce", members, "replace"},
+		{"count", []string{"counts", "count2"}, "counts"},
+		{"idx", []string{"index_of", "index"}, "index"},
+		{"len", members, ""},
+		{"xyz", members, ""},
+		{"lst", []string{"_", "list"}, "list"},
+		{"total", []string{"tot"}, ""},
+	}
+	for _, c := range cases {
+		if got := closestId(c.id, c.ids); got != c.want {
+			t.Errorf("closestId(%q) = %q, want %q", c.id, got, c.want)
+		}
+	}
+}
@@ -1,10 +1,25 @@
 package parser
 
+import (
+	"strings"
+
+	"github.com/the-xlang/xxc/lex/tokens"
+)
+
 type use struct {
 	Path       string
 	LinkString string
+	Alias      string
 	defs       *Defmap
 	tok        Tok
 	fullUse    bool
 	cppLink    bool
 }
+
+// nsIds returns namespace identifiers of use.
+func (u *use) nsIds() []string {
+	if u.Alias != "" {
+		return []string{u.Alias}
+	}
+	return strings.SplitN(u.LinkString, tokens.DOUBLE_COLON, -1)
+}
@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/the-xlang/xxc/internal/testutil"
+	"github.com/the-xlang/xxc/pkg/x"
+	"github.com/the-xlang/xxc/pkg/xio"
+)
+
+// parseProject writes files to project directory and
+// returns parser of main.xx file of project.
+func parseProject(t *testing.T, files map[string]string) *Parser {
+	t.Helper()
+	dir := t.TempDir()
+	testutil.WriteFiles(t, dir, files)
+	defer func(path string) { x.ProjectPath = path }(x.ProjectPath)
+	x.ProjectPath = dir
+	Reset()
+	f, err := xio.Openfx(filepath.Join(dir, "main.xx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := New(f)
+	p.Parsef(true, false)
+	return p
+}
+
+// errorRows returns rows of errors of key by file names.
+func errorRows(p *Parser, key string) map[string][]int {
+	rows := map[string][]int{}
+	for _, log := range p.Errors {
+		if log.Key == key {
+			name := filepath.Base(log.Path)
+			rows[name] = append(rows[name], log.Row)
+		}
+	}
+	return rows
+}
+
+// Packages which have same last segment of path.
+var utilFiles = map[string]string{
+	"a/util/util.xx": "pub one() int { ret 1 }\n",
+	"b/util/util.xx": "pub two() int { ret 2 }\n",
+}
+
+func TestUseAlias(t *testing.T) {
+	cases := []struct {
+		name string
+		main string
+		// Rows of use_alias_exist errors, no errors if empty.
+		rows []int
+	}{
+		{
+			name: "standard library",
+			main: "use std::math as m\n\nmain() {\n\toutln(m::abs(-1.0))\n}\n",
+		},
+		{
+			name: "same last segments",
+			main: "use a::util as au\nuse b::util as bu\n\nmain() {\n\toutln(au::one() + bu::two())\n}\n",
+		},
+		{
+			name: "duplicate alias",
+			main: "use a::util as u\nuse b::util as u\n\nmain() {}\n",
+			rows: []int{2},
+		},
+		{
+			name: "alias conflicts with namespace",
+			main: "use a::util\nuse b::util as a\n\nmain() {}\n",
+			rows: []int{2},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			files := map[string]string{"main.xx": c.main}
+			for name, text := range utilFiles {
+				files[name] = text
+			}
+			p := parseProject(t, files)
+			rows := errorRows(p, "use_alias_exist")["main.xx"]
+			switch {
+			case !reflect.DeepEqual(rows, c.rows):
+				t.Errorf("use_alias_exist rows are %v, want %v: %+v", rows, c.rows, p.Errors)
+			case c.rows == nil && len(p.Errors) > 0:
+				t.Errorf("unexpected errors: %+v", p.Errors)
+			}
+		})
+	}
+}
+
+func TestUseCycle(t *testing.T) {
+	cases := []struct {
+		name  string
+		files map[string]string
+		// Rows of use_cycle errors by file names.
+		rows  map[string][]int
+		chain string
+	}{
+		{
+			name: "cycle",
+			files: map[string]string{
+				"main.xx": "use a\n\nmain() {}\n",
+				"a/a.xx":  "use b\n",
+				"b/b.xx":  "use std::math\nuse c\n",
+				"c/c.xx":  "use a\n",
+			},
+			rows:  map[string][]int{"a.xx": {1}, "b.xx": {2}, "c.xx": {1}},
+			chain: "a -> b -> c -> a",
+		},
+		{
+			name: "self",
+			files: map[string]string{
+				"main.xx": "use a\n\nmain() {}\n",
+				"a/a.xx":  "use a\n",
+			},
+			rows:  map[string][]int{"a.xx": {1}},
+			chain: "a -> a",
+		},
+		{
+			name: "shared dependency",
+			files: map[string]string{
+				"main.xx": "use a\nuse b\n\nmain() {}\n",
+				"a/a.xx":  "use c\n",
+				"b/b.xx":  "use c\n",
+				"c/c.xx":  "pub f() {}\n",
+			},
+			rows: map[string][]int{},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := parseProject(t, c.files)
+			rows := errorRows(p, "use_cycle")
+			if !reflect.DeepEqual(rows, c.rows) {
+				t.Fatalf("use_cycle rows are %v, want %v: %+v", rows, c.rows, p.Errors)
+			}
+			for _, log := range p.Errors {
+				if log.Key == "use_cycle" && log.Args[0] != c.chain {
+					t.Errorf("chain is %v, want %s", log.Args[0], c.chain)
+				}
+			}
+		})
+	}
+}
@@ -251,31 +251,40 @@ func (ve *valueEvaluator) typeId(id string, t *Type) (_ value, _ bool) {
 func (ve *valueEvaluator) id() (_ value, ok bool) {
 	id := ve.tok.Kind
 
-	v, _, _ := ve.p.varById(id)
+	v, dm, _ := ve.p.varById(id)
 	if v != nil {
+		if dm == nil {
+			ve.p.pushref(ve.tok, SymbolVar, v)
+		} else {
+			ve.p.pushref(ve.tok, SymbolGlobal, v)
+		}
 		return ve.varId(id, v), true
 	}
 
 	f, _, _ := ve.p.FuncById(id)
 	if f != nil {
+		ve.p.pushref(ve.tok, SymbolFunc, f)
 		return ve.funcId(id, f), true
 	}
 
 	e, _, _ := ve.p.enumById(id)
 	if e != nil {
+		ve.p.pushref(ve.tok, SymbolEnum, e)
 		return ve.enumId(id, e), true
 	}
 
 	s, _, _ := ve.p.structById(id)
 	if s != nil {
+		ve.p.pushref(ve.tok, SymbolStruct, s)
 		return ve.structId(id, s), true
 	}
 
 	t, _, _ := ve.p.typeById(id)
 	if t != nil {
+		ve.p.pushref(ve.tok, SymbolType, t)
 		return ve.typeId(id, t)
 	}
 
-	ve.p.eval.pusherrtok(ve.tok, "id_noexist", id)
+	ve.p.eval.pushnoexist(ve.tok, "id_noexist", ve.p.scopeIds("gfest"))
 	return
 }
@@ -0,0 +1,153 @@
+package x
+
+// Error codes by error keys.
+// Codes are stable, never change or reuse a code; append new ones.
+var ErrorCodes = map[string]string{
+	`no_stdlib`:                                `X0001`,
+	`file_not_useable`:                         `X0002`,
+	`file_not_x`:                               `X0003`,
+	`invalid_token`:                            `X0004`,
+	`invalid_syntax`:                           `X0005`,
+	`no_entry_point`:                           `X0006`,
+	`exist_id`:                                 `X0007`,
+	`extra_closed_parentheses`:                 `X0008`,
+	`extra_closed_braces`:                      `X0009`,
+	`extra_closed_brackets`:                    `X0010`,
+	`wait_close_parentheses`:                   `X0011`,
+	`wait_close_brace`:                         `X0012`,
+	`wait_close_bracket`:                       `X0013`,
+	`expected_parentheses_close`:               `X0014`,
+	`expected_brace_close`:                     `X0015`,
+	`expected_bracket_close`:                   `X0016`,
+	`body_not_exist`:                           `X0017`,
+	`operator_overflow`:                        `X0018`,
+	`incompatible_datatype`:                    `X0019`,
+	`operator_notfor_xtype`:                    `X0020`,
+	`operator_notfor_float`:                    `X0021`,
+	`operator_notfor_int`:                      `X0022`,
+	`operator_notfor_uint`:                     `X0023`,
+	`id_noexist`:                               `X0024`,
+	`not_function_call`:                        `X0025`,
+	`argument_overflow`:                        `X0026`,
+	`func_have_return`:                         `X0027`,
+	`func_have_parameters`:                     `X0028`,
+	`func_have_attributes`:                     `X0029`,
+	`require_return_value`:                     `X0030`,
+	`void_function_return_value`:               `X0031`,
+	`bitshift_must_unsigned`:                   `X0032`,
+	`logical_not_bool`:                         `X0033`,
+	`assign_const`:                             `X0034`,
+	`assign_nonlvalue`:                         `X0035`,
+	`assign_type_not_support_value`:            `X0036`,
+	`invalid_type`:                             `X0037`,
+	`invalid_attribute`:                        `X0038`,
+	`invalid_numeric_range`:                    `X0039`,
+	`invalid_escape_sequence`:                  `X0040`,
+	`invalid_type_source`:                      `X0041`,
+	`invalid_preprocessor`:                     `X0042`,
+	`invalid_pragma_directive`:                 `X0043`,
+	`invalid_type_for_const`:                   `X0044`,
+	`invalid_value_for_key`:                    `X0045`,
+	`invalid_expr`:                             `X0046`,
+	`invalid_header_ext`:                       `X0047`,
+	`missing_autotype_value`:                   `X0048`,
+	`missing_type`:                             `X0049`,
+	`missing_expr`:                             `X0050`,
+	`missing_block_comment`:                    `X0051`,
+	`missing_rune_end`:                         `X0052`,
+	`missing_ret`:                              `X0053`,
+	`missing_string_end`:                       `X0054`,
+	`missing_const_value`:                      `X0055`,
+	`missing_multi_return`:                     `X0056`,
+	`missing_multiassign_identifiers`:          `X0057`,
+	`missing_use_path`:                         `X0058`,
+	`missing_pragma_directive`:                 `X0059`,
+	`missing_goto_label`:                       `X0060`,
+	`missing_expr_for`:                         `X0061`,
+	`missing_generics`:                         `X0062`,
+	`expr_not_const`:                           `X0063`,
+	`nil_for_autotype`:                         `X0064`,
+	`void_for_autotype`:                        `X0065`,
+	`rune_empty`:                               `X0066`,
+	`rune_overflow`:                            `X0067`,
+	`not_supports_indexing`:                    `X0068`,
+	`not_supports_slicing`:                     `X0069`,
+	`undefined_attribute`:                      `X0070`,
+	`attribute_repeat`:                         `X0071`,
+	`already_constant`:                         `X0072`,
+	`already_variadic`:                         `X0073`,
+	`already_reference`:                        `X0074`,
+	`already_uses`:                             `X0075`,
+	`ignore_id`:                                `X0076`,
+	`overflow_multiassign_identifiers`:         `X0077`,
+	`overflow_return`:                          `X0078`,
+	`break_at_outiter`:                         `X0079`,
+	`continue_at_outiter`:                      `X0080`,
+	`iter_while_notbool_expr`:                  `X0081`,
+	`iter_foreach_nonenumerable_expr`:          `X0082`,
+	`much_foreach_vars`:                        `X0083`,
+	`if_notbool_expr`:                          `X0084`,
+	`else_have_expr`:                           `X0085`,
+	`variadic_parameter_notlast`:               `X0086`,
+	`variadic_with_nonvariadicable`:            `X0087`,
+	`more_args_with_variadiced`:                `X0088`,
+	`type_notsupports_casting`:                 `X0089`,
+	`type_notsupports_casting_to`:              `X0090`,
+	`notallow_declares`:                        `X0091`,
+	`notallow_multiple_assign`:                 `X0092`,
+	`attribute_not_supports`:                   `X0093`,
+	`generics_not_supports`:                    `X0094`,
+	`use_at_content`:                           `X0095`,
+	`use_not_found`:                            `X0096`,
+	`use_has_errors`:                           `X0097`,
+	`def_not_support_pub`:                      `X0098`,
+	`obj_not_support_sub_fields`:               `X0099`,
+	`obj_have_not_id`:                          `X0100`,
+	`doc_couldnt_generated`:                    `X0101`,
+	`declared_but_not_used`:                    `X0102`,
+	`expr_not_func_call`:                       `X0103`,
+	`label_exist`:                              `X0104`,
+	`label_not_exist`:                          `X0105`,
+	`goto_jumps_declarations`:                  `X0106`,
+	`function_not_has_parameter`:               `X0107`,
+	`already_has_expr`:                         `X0108`,
+	`argument_must_target_to_parameter`:        `X0109`,
+	`namespace_not_exist`:                      `X0110`,
+	`overflow_limits`:                          `X0111`,
+	`generics_overflow`:                        `X0112`,
+	`has_generics`:                             `X0113`,
+	`not_has_generics`:                         `X0114`,
+	`not_lvalue_for_reference_param`:           `X0115`,
+	`variadic_reference_param`:                 `X0116`,
+	`func_must_have_generics_if_has_attribute`: `X0117`,
+	`func_cant_have_params_if_has_attribute`:   `X0118`,
+	`func_cant_have_ret_if_has_attribute`:      `X0119`,
+	`func_cant_have_generics_if_has_attribute`: `X0120`,
+	`divide_by_zero`:                           `X0121`,
+	`trait_hasnt_id`:                           `X0122`,
+	`notimpl_trait_def`:                        `X0123`,
+	`dynamic_generic_annotation_failed`:        `X0124`,
+	`fallthrough_wrong_use`:                    `X0125`,
+	`fallthrough_into_final_case`:              `X0126`,
+	`compiler_not_found`:                       `X0127`,
+	`compilation_failed`:                       `X0128`,
+	`use_alias_exist`:                          `X0129`,
+	`use_cycle`:                                `X0130`,
+	`settings_not_found`:                       `X0131`,
+	`settings_has_errors`:                      `X0132`,
+	`format_failed`:                            `X0133`,
+	`missing_warning_key`:                      `X0134`,
+	`too_many_errors`:                          `X0135`,
+	`id_not_pub`:                               `X0136`,
+	`compiler_crashed`:                         `X0137`,
+	`invalid_operator`:                         `X0138`,
+	`invalid_type_unary_operator`:              `X0139`,
+	`undefined_warning_key`:                    `X0140`,
+}
+
+// Warning codes by warning keys.
+// Codes are stable, never change or reuse a code; append new ones.
+var WarningCodes = map[string]string{
+	`doc_ignored`:         `W0001`,
+	`exist_undefined_doc`: `W0002`,
+}
@@ -124,12 +124,26 @@ var Errors = map[string]string{
 	`variadic_reference_param`:                 `referencing cannot combined with variadic parameters`,
 	`func_must_have_generics_if_has_attribute`: `function is must be have minimum one generic type if has @%s attribute`,
 	`func_cant_have_params_if_has_attribute`:   `function is cannot have parameter(s) if has @%s attribute`,
+	`func_cant_have_ret_if_has_attribute`:      `function is cannot have return type if has @%s attribute`,
+	`func_cant_have_generics_if_has_attribute`: `function is cannot have generic type(s) if has @%s attribute`,
 	`divide_by_zero`:                           `divide by zero`,
 	`trait_hasnt_id`:                           `%s trait is not have this identifier: %s`,
 	`notimpl_trait_def`:                        `not implemented %s trait's %s define`,
 	`dynamic_generic_annotation_failed`:        `dynamic generic type annotation failed`,
 	`fallthrough_wrong_use`:                    `fallthrough keyword can only useable at end of the case scopes`,
 	`fallthrough_into_final_case`:              `fallthrough cannot useable at final case`,
+	`compiler_not_found`:                       `C++ compiler not found: %s`,
+	`compilation_failed`:                       `C++ compiler exited with code %d`,
+	`use_alias_exist`:                          `use alias is already exist: %s`,
+	`use_cycle`:                                `use cycle is not allowed: %s`,
+	`settings_not_found`:                       `X settings file is not found: %s`,
+	`settings_has_errors`:                      `X settings has errors: %s`,
+	`format_failed`:                            `%s: source code could not formatted safely`,
+	`missing_warning_key`:                      `missing warning key`,
+	`too_many_errors`:                          `too many errors, %d more errors are not shown`,
+	`id_not_pub`:                               `identifier is not public: %s`,
+	`compiler_crashed`:                         `compiler is crashed: %v`,
+	`undefined_warning_key`:                    `undefined warning key: %s`,
 }
 
 // GetError returns error.
@@ -4,4 +4,5 @@ package x
 var Attributes = [...]string{
 	0: Attribute_Inline,
 	1: Attribute_TypeArg,
+	2: Attribute_Test,
 }
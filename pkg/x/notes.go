@@ -0,0 +1,22 @@
+package x
+
+import "fmt"
+
+// Note messages of related locations of logs.
+var Notes = map[string]string{
+	`declared_here`:      `previously declared here`,
+	`used_here`:          `previously used here`,
+	`trait_def_here`:     `definition of trait is here`,
+	`defined_here`:       `defined here`,
+	`did_you_mean`:       `did you mean %s?`,
+	`remove_declaration`: `remove the declaration`,
+	`ignore_value`:       `assign the value to ignore operator`,
+	`add_ret`:            `add return statement`,
+	`move_use`:           `move the use declaration to head of file`,
+	`add_pub`:            `add pub modifier to the definition`,
+}
+
+// GetNote returns note.
+func GetNote(key string, args ...any) string {
+	return fmt.Sprintf(Notes[key], args...)
+}
@@ -7,6 +7,7 @@ const (
 	Version       = `@developer_beta 0.0.1`
 	SrcExt        = `.xx`
 	DocExt        = SrcExt + "doc"
+	TestSuffix    = "_test"
 	SettingsFile  = "x.set"
 	Stdlib        = "std"
 	Localizations = "localization"
@@ -29,9 +30,11 @@ const (
 
 	Attribute_Inline  = "inline"
 	Attribute_TypeArg = "typearg"
+	Attribute_Test    = "test"
 
 	PreprocessorDirective      = "pragma"
 	PreprocessorDirectiveEnofi = "enofi"
+	PreprocessorDirectiveAllow = "allow"
 
 	Mark_Array = "..."
 
@@ -41,8 +44,9 @@ const (
 
 // Environment Variables.
 var (
-	LangsPath  string
-	StdlibPath string
-	ExecPath   string
-	Set        *xset.XSet
+	LangsPath   string
+	StdlibPath  string
+	ExecPath    string
+	ProjectPath string
+	Set         *xset.XSet
 )
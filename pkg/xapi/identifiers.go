@@ -2,9 +2,12 @@ package xapi
 
 import (
 	"fmt"
+	"hash/fnv"
+	"path/filepath"
 	"strings"
-	"unsafe"
+	"sync"
 
+	"github.com/the-xlang/xxc/pkg/x"
 	"github.com/the-xlang/xxc/pkg/xio"
 )
 
@@ -14,6 +17,12 @@ const Ignore = "_"
 // InitializerCaller identifier.
 const InitializerCaller = "_xxc___call_initializers"
 
+// TestList identifier.
+const TestList = "_xxc___tests"
+
+// TestMacro is the macro for compile tests instead of entry point.
+const TestMacro = "_XXC_TEST"
+
 const typeExtension = "_xt"
 
 // IsIgnoreId reports identifier is ignore or not.
@@ -26,16 +35,46 @@ func AsId(id string) string {
 	return "XID(" + id + ")"
 }
 
-func getPtrAsId(ptr unsafe.Pointer) string {
-	address := fmt.Sprintf("%p", ptr)
-	address = address[3:] // skip 0xc
-	for i, r := range address {
-		if r != '0' {
-			address = address[i:]
-			break
+// fileIds are the cache of identifiers of files.
+var fileIds sync.Map
+
+// relPath returns path of file relative to standard library
+// or project if possible, absolute path if not.
+func relPath(f *xio.File) string {
+	path := f.Path()
+	rel := func(root string) (string, bool) {
+		if root == "" {
+			return "", false
 		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return "", false
+		}
+		return filepath.ToSlash(rel), true
+	}
+	// Paths can not have NUL, so files of standard library
+	// are not same with files of std directory of project.
+	if rel, ok := rel(x.StdlibPath); ok {
+		return x.Stdlib + "\x00" + rel
+	}
+	if rel, ok := rel(x.ProjectPath); ok {
+		return rel
+	}
+	return filepath.ToSlash(path)
+}
+
+// getFileId returns identifier of file.
+// Identifier is stable, derived from relative path of file.
+// Hash is 64-bit, so identifiers of files are not collide in practice.
+func getFileId(f *xio.File) string {
+	if id, ok := fileIds.Load(f); ok {
+		return id.(string)
 	}
-	return address
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(relPath(f)))
+	id := fmt.Sprintf("%016x", h.Sum64())
+	fileIds.Store(f, id)
+	return id
 }
 
 // OutId returns cpp output identifier form of given identifier.
@@ -43,7 +82,7 @@ func OutId(id string, f *xio.File) string {
 	if f != nil {
 		var out strings.Builder
 		out.WriteByte('f')
-		out.WriteString(getPtrAsId(unsafe.Pointer(f)))
+		out.WriteString(getFileId(f))
 		out.WriteByte('_')
 		out.WriteString(id)
 		return out.String()
@@ -0,0 +1,58 @@
+package xapi
+
+import (
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/the-xlang/xxc/pkg/x"
+	"github.com/the-xlang/xxc/pkg/xio"
+)
+
+func TestOutId(t *testing.T) {
+	defer func(std, project string) {
+		x.StdlibPath, x.ProjectPath = std, project
+	}(x.StdlibPath, x.ProjectPath)
+	root := t.TempDir()
+	x.StdlibPath = filepath.Join(root, "std")
+	x.ProjectPath = filepath.Join(root, "project")
+	file := func(path string) *xio.File {
+		f := new(xio.File)
+		f.Dir, f.Name = filepath.Split(filepath.Join(root, path))
+		return f
+	}
+	paths := []string{
+		"project/main.xx",
+		"project/lib/lib.xx",
+		"project/lib/util.xx",
+		"project/std/math/math.xx",
+		"std/math/math.xx",
+		"std/math/hyperbolic.xx",
+		"other/main.xx",
+	}
+	format := regexp.MustCompile(`^f[0-9a-f]{16}_total$`)
+	ids := map[string]string{}
+	for _, path := range paths {
+		id := OutId("total", file(path))
+		if !format.MatchString(id) {
+			t.Errorf("%s: invalid identifier %s", path, id)
+		}
+		if other, ok := ids[id]; ok {
+			t.Errorf("%s and %s: same identifier %s", path, other, id)
+		}
+		ids[id] = path
+		// Identifiers are derived from paths, not from cached files.
+		if again := OutId("total", file(path)); again != id {
+			t.Errorf("%s: identifier is not stable: %s and %s", path, id, again)
+		}
+	}
+	// Identifiers are relative to project, so same in any directory.
+	moved := OutId("total", file("project/lib/lib.xx"))
+	x.ProjectPath = filepath.Join(root, "other")
+	if id := OutId("total", file("other/lib/lib.xx")); id != moved {
+		t.Errorf("identifier depends on directory of project: %s and %s", moved, id)
+	}
+	if id := OutId("total", nil); id != AsId("total") {
+		t.Errorf("identifier without file: %s", id)
+	}
+}
@@ -0,0 +1,63 @@
+package xcompiler
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+
+	"github.com/the-xlang/xxc/pkg/x"
+	"github.com/the-xlang/xxc/pkg/xlog"
+	"github.com/the-xlang/xxc/pkg/xset"
+)
+
+// Args returns command-line arguments of C++ compiler
+// for compile src file to out executable.
+func Args(set *xset.XSet, src, out string) []string {
+	var args []string
+	if set.CppStandard != "" {
+		args = append(args, "-std="+set.CppStandard)
+	}
+	args = append(args, set.CompilerFlags...)
+	for _, dir := range set.IncludeDirs {
+		args = append(args, "-I"+dir)
+	}
+	args = append(args, "-o", out, src)
+	// Libraries must be after of sources for linker.
+	for _, lib := range set.LinkLibs {
+		args = append(args, "-l"+lib)
+	}
+	return args
+}
+
+// Compile compiles C++ source file to executable by settings.
+// Returns logs of compiler and reports compilation is successful or not.
+// Warnings of compiler are returned even if compilation is successful.
+func Compile(set *xset.XSet, src, out string) ([]xlog.CompilerLog, bool) {
+	if set.Compiler == "" {
+		return []xlog.CompilerLog{flatError("compiler_not_found", set.Compiler)}, false
+	}
+	var output bytes.Buffer
+	cmd := exec.Command(set.Compiler, Args(set, src, out)...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := cmd.Run()
+	if err == nil {
+		return outputLogs(output.String(), false), true
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return []xlog.CompilerLog{flatError("compiler_not_found", set.Compiler)}, false
+	}
+	logs := outputLogs(output.String(), true)
+	logs = append(logs, flatError("compilation_failed", exitErr.ExitCode()))
+	return logs, false
+}
+
+func flatError(key string, args ...any) xlog.CompilerLog {
+	return xlog.CompilerLog{
+		Type:    xlog.FlatError,
+		Message: x.GetError(key, args...),
+		Key:     key,
+		Args:    args,
+	}
+}
@@ -0,0 +1,45 @@
+package xcompiler
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/the-xlang/xxc/pkg/xset"
+)
+
+func TestArgs(t *testing.T) {
+	cases := []struct {
+		name string
+		set  xset.XSet
+		want []string
+	}{
+		{
+			name: "default",
+			set:  *xset.Default,
+			want: []string{"-std=c++17", "-o", "main", "x.cpp"},
+		},
+		{
+			name: "without standard",
+			set:  xset.XSet{},
+			want: []string{"-o", "main", "x.cpp"},
+		},
+		{
+			name: "flags, include directories and libraries",
+			set: xset.XSet{
+				CppStandard:   "c++20",
+				CompilerFlags: []string{"-O2", "-Wall"},
+				IncludeDirs:   []string{"include", "vendor"},
+				LinkLibs:      []string{"m", "pthread"},
+			},
+			// Libraries are after source file for linker.
+			want: []string{"-std=c++20", "-O2", "-Wall", "-Iinclude", "-Ivendor", "-o", "main", "x.cpp", "-lm", "-lpthread"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Args(&c.set, "x.cpp", "main"); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
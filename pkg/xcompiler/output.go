@@ -0,0 +1,196 @@
+package xcompiler
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/the-xlang/xxc/pkg/x"
+	"github.com/the-xlang/xxc/pkg/xlog"
+)
+
+// Located line of GCC and Clang output.
+// Column is not exist if compiler is not shows columns.
+// Windows drive letters are not matched as position because of digits.
+var locatedRegexp = regexp.MustCompile(`^(.+?):(\d+):(?:(\d+):)?\s*(.*)$`)
+
+// Caret line of source code which is shown under the line.
+var caretRegexp = regexp.MustCompile(`^\s*[~^]+\s*$`)
+
+// Summary line of Clang output such as "1 error generated.".
+var summaryRegexp = regexp.MustCompile(`^\d+ (errors?|warnings?)( and \d+ (errors?|warnings?))? generated\.$`)
+
+// Severities of compiler messages.
+const (
+	sevContext = iota // Context of message such as instantiation stacks.
+	sevNote
+	sevWarning
+	sevError
+)
+
+// message is a line of compiler output.
+type message struct {
+	severity int
+	path     string
+	row      int
+	column   int
+	text     string
+}
+
+func (m *message) isX() bool { return strings.HasSuffix(m.path, x.SrcExt) }
+
+// diagnostic is an error or warning of compiler with
+// contexts before and notes after it.
+type diagnostic struct {
+	main    message
+	context []message
+	notes   []message
+}
+
+// parseLine returns message of located line.
+// Reports false if line is not located.
+func parseLine(line string) (message, bool) {
+	parts := locatedRegexp.FindStringSubmatch(line)
+	if parts == nil {
+		return message{}, false
+	}
+	m := message{severity: sevContext, path: parts[1], text: parts[4]}
+	m.row, _ = strconv.Atoi(parts[2])
+	m.column, _ = strconv.Atoi(parts[3])
+	for _, sev := range [...]struct {
+		prefix   string
+		severity int
+	}{
+		{"fatal error: ", sevError},
+		{"error: ", sevError},
+		{"warning: ", sevWarning},
+		{"note: ", sevNote},
+	} {
+		if strings.HasPrefix(m.text, sev.prefix) {
+			m.severity = sev.severity
+			m.text = m.text[len(sev.prefix):]
+			break
+		}
+	}
+	return m, true
+}
+
+// xPosition returns X source position of diagnostic.
+// The nearest position to the user code is preferred:
+// position of diagnostic itself, instantiation contexts
+// of GCC, and then instantiation notes of Clang.
+// Reports false if diagnostic is not mappable to X source code.
+func (d *diagnostic) xPosition() (message, bool) {
+	if d.main.isX() {
+		return d.main, true
+	}
+	// GCC prints outermost context at last, which is the user code.
+	for i := len(d.context) - 1; i >= 0; i-- {
+		if d.context[i].isX() {
+			return d.context[i], true
+		}
+	}
+	// Clang prints instantiation stack as notes after message.
+	for i := len(d.notes) - 1; i >= 0; i-- {
+		if d.notes[i].isX() {
+			return d.notes[i], true
+		}
+	}
+	return message{}, false
+}
+
+func (d *diagnostic) log() xlog.CompilerLog {
+	clog := xlog.CompilerLog{Message: d.main.text}
+	pos, ok := d.xPosition()
+	if !ok {
+		pos = d.main
+		clog.Internal = true
+	}
+	clog.Path = pos.path
+	clog.Row = pos.row
+	// Columns of X source code are unknown,
+	// column of compiler is column of C++ code.
+	if !ok {
+		clog.Column = pos.column
+	}
+	if d.main.severity == sevError {
+		clog.Type = xlog.Error
+	} else {
+		clog.Type = xlog.Warning
+	}
+	return clog
+}
+
+// outputLogs returns logs of compiler output.
+// Messages are mapped to X source code by line directives if possible,
+// others are marked as internal. Unlocated lines such as linker
+// errors are returned as internal flat errors if compilation is
+// failed, as internal flat warnings if not.
+func outputLogs(output string, failed bool) []xlog.CompilerLog {
+	flatType := xlog.FlatWarning
+	if failed {
+		flatType = xlog.FlatError
+	}
+	var logs []xlog.CompilerLog
+	var context []message
+	var last *diagnostic
+	flush := func() {
+		if last == nil {
+			return
+		}
+		clog := last.log()
+		// Templates may cause same message for same line many times.
+		for _, l := range logs {
+			if l.Type == clog.Type && l.Path == clog.Path && l.Row == clog.Row &&
+				l.Column == clog.Column && l.Message == clog.Message {
+				last = nil
+				return
+			}
+		}
+		logs = append(logs, clog)
+		last = nil
+	}
+	lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		switch {
+		case strings.TrimSpace(line) == "",
+			// Source code lines, carets and continuation of include stacks.
+			line[0] == ' ' || line[0] == '\t',
+			caretRegexp.MatchString(line),
+			// Clang shows source code lines without indentation.
+			i+1 < len(lines) && caretRegexp.MatchString(lines[i+1]),
+			summaryRegexp.MatchString(line):
+			continue
+		}
+		m, ok := parseLine(line)
+		switch {
+		case !ok:
+			// Unlocated contexts such as "In file included from" and
+			// "In function" are belongs to next message.
+			if strings.HasPrefix(line, "In file included from ") ||
+				strings.HasSuffix(line, ":") && strings.Contains(line, ": In ") {
+				flush()
+				continue
+			}
+			flush()
+			logs = append(logs, xlog.CompilerLog{
+				Type:     flatType,
+				Message:  line,
+				Internal: true,
+			})
+		case m.severity == sevContext:
+			flush()
+			context = append(context, m)
+		case m.severity == sevNote:
+			if last != nil {
+				last.notes = append(last.notes, m)
+			}
+		default:
+			flush()
+			last = &diagnostic{main: m, context: context}
+			context = nil
+		}
+	}
+	flush()
+	return logs
+}
@@ -0,0 +1,209 @@
+package xcompiler
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/the-xlang/xxc/pkg/xlog"
+)
+
+// Outputs of GCC and Clang for C++ code which has line
+// directives to /p/main.xx, source of X is not exist so compilers
+// are not shows lines of it.
+const (
+	gccOutput = `/p/main.xx: In function 'int h()':
+/p/main.xx:8:18: error: invalid conversion from 'const char*' to 'int' [-fpermissive]
+/p/main.xx: In function 'int main()':
+/p/main.xx:9:18: warning: unused variable 'unused_var' [-Wunused-variable]
+a.cpp: In instantiation of 'void f(T) [with T = int]':
+/p/main.xx:4:13:   required from here
+a.cpp:2:38: error: request for member 'missing' in 't', which is of non-class type 'int'
+    2 | template<typename T> void f(T t) { t.missing(); }
+      |                                    ~~^~~~~~~
+`
+	gccNoColumnOutput = `/p/main.xx: In function 'int main()':
+/p/main.xx:6: error: too few arguments to function 'void k(int, int)'
+/p/main.xx:3: note: declared here
+`
+	gccLinkerOutput = "/usr/bin/ld: /tmp/ccxQ2osM.o: in function `main':\n" +
+		"b.cpp:(.text+0x5): undefined reference to `x()'\n" +
+		"collect2: error: ld returned 1 exit status\n"
+	clangOutput = `a.cpp:2:37: error: member reference base type 'int' is not a structure or union
+template<typename T> void f(T t) { t.missing(); }
+                                   ~^~~~~~~~
+/p/main.xx:4:12: note: in instantiation of function template specialization 'f<int>' requested here
+a.cpp:7:5: warning: unused variable 'y' [-Wunused-variable]
+int y;
+    ^
+1 warning and 1 error generated.
+`
+)
+
+func TestParseLine(t *testing.T) {
+	cases := []struct {
+		line string
+		want message
+		ok   bool
+	}{
+		{
+			line: "/p/main.xx:8:18: error: invalid conversion",
+			want: message{sevError, "/p/main.xx", 8, 18, "invalid conversion"},
+			ok:   true,
+		},
+		{
+			line: "a.cpp:1:10: fatal error: missing.hpp: No such file or directory",
+			want: message{sevError, "a.cpp", 1, 10, "missing.hpp: No such file or directory"},
+			ok:   true,
+		},
+		{
+			line: "/p/main.xx:9:18: warning: unused variable 'v'",
+			want: message{sevWarning, "/p/main.xx", 9, 18, "unused variable 'v'"},
+			ok:   true,
+		},
+		{
+			line: "/p/main.xx:3: note: declared here",
+			want: message{sevNote, "/p/main.xx", 3, 0, "declared here"},
+			ok:   true,
+		},
+		{
+			line: "/p/main.xx:4:13:   required from here",
+			want: message{sevContext, "/p/main.xx", 4, 13, "required from here"},
+			ok:   true,
+		},
+		{line: "a.cpp: In instantiation of 'void f(T) [with T = int]':"},
+		{line: "b.cpp:(.text+0x5): undefined reference to `x()'"},
+		{line: "collect2: error: ld returned 1 exit status"},
+	}
+	for _, c := range cases {
+		m, ok := parseLine(c.line)
+		if ok != c.ok || m != c.want {
+			t.Errorf("parseLine(%q): got %+v %v, want %+v %v", c.line, m, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestXPosition(t *testing.T) {
+	x := message{sevContext, "/p/main.xx", 4, 13, "required from here"}
+	cpp := message{sevError, "a.cpp", 2, 38, "error"}
+	cases := []struct {
+		name string
+		d    diagnostic
+		want message
+		ok   bool
+	}{
+		{
+			name: "main",
+			d:    diagnostic{main: message{sevError, "/p/main.xx", 8, 18, "error"}},
+			want: message{sevError, "/p/main.xx", 8, 18, "error"},
+			ok:   true,
+		},
+		{
+			name: "outermost context",
+			d: diagnostic{main: cpp, context: []message{
+				{sevContext, "/p/main.xx", 1, 1, "inner"}, x,
+			}},
+			want: x,
+			ok:   true,
+		},
+		{
+			name: "outermost note",
+			d: diagnostic{main: cpp, notes: []message{
+				{sevNote, "/p/main.xx", 1, 1, "inner"}, x,
+			}},
+			want: x,
+			ok:   true,
+		},
+		{
+			name: "context before notes",
+			d: diagnostic{
+				main:    cpp,
+				context: []message{x},
+				notes:   []message{{sevNote, "/p/main.xx", 1, 1, "note"}},
+			},
+			want: x,
+			ok:   true,
+		},
+		{
+			name: "internal",
+			d:    diagnostic{main: cpp, notes: []message{{sevNote, "a.cpp", 1, 1, "note"}}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m, ok := c.d.xPosition()
+			if ok != c.ok || m != c.want {
+				t.Errorf("got %+v %v, want %+v %v", m, ok, c.want, c.ok)
+			}
+		})
+	}
+}
+
+func TestOutputLogs(t *testing.T) {
+	cases := []struct {
+		name   string
+		output string
+		failed bool
+		want   []xlog.CompilerLog
+	}{
+		{
+			name:   "gcc",
+			output: gccOutput,
+			failed: true,
+			want: []xlog.CompilerLog{
+				{Type: xlog.Error, Path: "/p/main.xx", Row: 8, Message: "invalid conversion from 'const char*' to 'int' [-fpermissive]"},
+				{Type: xlog.Warning, Path: "/p/main.xx", Row: 9, Message: "unused variable 'unused_var' [-Wunused-variable]"},
+				{Type: xlog.Error, Path: "/p/main.xx", Row: 4, Message: "request for member 'missing' in 't', which is of non-class type 'int'"},
+			},
+		},
+		{
+			name:   "gcc without columns",
+			output: gccNoColumnOutput,
+			failed: true,
+			want: []xlog.CompilerLog{
+				{Type: xlog.Error, Path: "/p/main.xx", Row: 6, Message: "too few arguments to function 'void k(int, int)'"},
+			},
+		},
+		{
+			name:   "gcc linker",
+			output: gccLinkerOutput,
+			failed: true,
+			want: []xlog.CompilerLog{
+				{Type: xlog.FlatError, Message: "/usr/bin/ld: /tmp/ccxQ2osM.o: in function `main':", Internal: true},
+				{Type: xlog.FlatError, Message: "b.cpp:(.text+0x5): undefined reference to `x()'", Internal: true},
+				{Type: xlog.FlatError, Message: "collect2: error: ld returned 1 exit status", Internal: true},
+			},
+		},
+		{
+			name:   "clang",
+			output: clangOutput,
+			failed: true,
+			want: []xlog.CompilerLog{
+				{Type: xlog.Error, Path: "/p/main.xx", Row: 4, Message: "member reference base type 'int' is not a structure or union"},
+				{Type: xlog.Warning, Path: "a.cpp", Row: 7, Column: 5, Message: "unused variable 'y' [-Wunused-variable]", Internal: true},
+			},
+		},
+		{
+			name:   "successful",
+			output: "/usr/bin/ld: warning: a.o: missing .note.GNU-stack section implies executable stack\n",
+			want: []xlog.CompilerLog{
+				{Type: xlog.FlatWarning, Message: "/usr/bin/ld: warning: a.o: missing .note.GNU-stack section implies executable stack", Internal: true},
+			},
+		},
+		{
+			name:   "windows line endings",
+			output: "/p/main.xx:6: error: too few arguments\r\n",
+			failed: true,
+			want: []xlog.CompilerLog{
+				{Type: xlog.Error, Path: "/p/main.xx", Row: 6, Message: "too few arguments"},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			logs := outputLogs(c.output, c.failed)
+			if !reflect.DeepEqual(logs, c.want) {
+				t.Errorf("got:\n%+v\nwant:\n%+v", logs, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package xdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Context line count of hunks.
+const context = 3
+
+// Edit kinds.
+const (
+	equal  = ' '
+	delete = '-'
+	insert = '+'
+)
+
+type edit struct {
+	kind byte
+	line string
+	// Line numbers of a and b, starts at 1.
+	aline int
+	bline int
+}
+
+// lines returns lines of text, without line endings.
+func lines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	text = strings.TrimSuffix(text, "\n")
+	return strings.Split(text, "\n")
+}
+
+// edits returns shortest edit script of a to b with Myers' algorithm.
+func edits(a, b []string) []edit {
+	n, m := len(a), len(b)
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+2)
+	var trace [][]int
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace, offset)
+			}
+		}
+	}
+	return nil
+}
+
+func backtrack(a, b []string, trace [][]int, offset int) []edit {
+	x, y := len(a), len(b)
+	var script []edit
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevk int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevk = k + 1
+		} else {
+			prevk = k - 1
+		}
+		prevx := v[offset+prevk]
+		prevy := prevx - prevk
+		for x > prevx && y > prevy {
+			x--
+			y--
+			script = append(script, edit{equal, a[x], x + 1, y + 1})
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevx {
+			y--
+			script = append(script, edit{insert, b[y], x, y + 1})
+		} else {
+			x--
+			script = append(script, edit{delete, a[x], x + 1, y})
+		}
+	}
+	// Script is reversed because of backtracking.
+	for i, j := 0, len(script)-1; i < j; i, j = i+1, j-1 {
+		script[i], script[j] = script[j], script[i]
+	}
+	return script
+}
+
+// hunkRange returns unified diff range of hunk.
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	// Empty ranges are point to line before.
+	if count == 0 {
+		start--
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func writeHunk(sb *strings.Builder, script []edit) {
+	astart, bstart := script[0].aline, script[0].bline
+	acount, bcount := 0, 0
+	for _, e := range script {
+		switch e.kind {
+		case equal:
+			acount++
+			bcount++
+		case delete:
+			acount++
+		case insert:
+			bcount++
+		}
+	}
+	// Starts are line before of first line for pure inserts and deletes.
+	if script[0].kind == insert {
+		astart++
+	} else if script[0].kind == delete {
+		bstart++
+	}
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(astart, acount), hunkRange(bstart, bcount))
+	for _, e := range script {
+		sb.WriteByte(e.kind)
+		sb.WriteString(e.line)
+		sb.WriteByte('\n')
+	}
+}
+
+// Unified returns unified diff of texts with file names.
+// Returns empty string if texts are equal.
+func Unified(aname, bname, a, b string) string {
+	if a == b {
+		return ""
+	}
+	script := edits(lines(a), lines(b))
+	var sb strings.Builder
+	sb.WriteString("--- " + aname + "\n")
+	sb.WriteString("+++ " + bname + "\n")
+	for i := 0; i < len(script); {
+		// Find next change.
+		for i < len(script) && script[i].kind == equal {
+			i++
+		}
+		if i == len(script) {
+			break
+		}
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		// Extend hunk while changes are close.
+		end := i
+		for end < len(script) {
+			if script[end].kind != equal {
+				end++
+				continue
+			}
+			j := end
+			for j < len(script) && script[j].kind == equal {
+				j++
+			}
+			if j == len(script) || j-end > 2*context {
+				end += context
+				if end > len(script) {
+					end = len(script)
+				}
+				break
+			}
+			end = j
+		}
+		writeHunk(&sb, script[start:end])
+		i = end
+	}
+	return sb.String()
+}
@@ -0,0 +1,98 @@
+package xdiff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// numbers returns lines of numbers from 1 to n.
+// Lines of changes are replaced with texts of changes.
+func numbers(n int, changes map[int]string) string {
+	var sb strings.Builder
+	for i := 1; i <= n; i++ {
+		if text, ok := changes[i]; ok {
+			sb.WriteString(text)
+		} else {
+			fmt.Fprint(&sb, i)
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// Expected diffs are same as output of GNU diff -u.
+func TestUnified(t *testing.T) {
+	cases := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "equal",
+			a:    numbers(10, nil),
+			b:    numbers(10, nil),
+			want: "",
+		},
+		{
+			name: "change in middle",
+			a:    numbers(10, nil),
+			b:    numbers(10, map[int]string{5: "five"}),
+			want: "@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name: "insert at start",
+			a:    numbers(10, nil),
+			b:    "0\n" + numbers(10, nil),
+			want: "@@ -1,3 +1,4 @@\n+0\n 1\n 2\n 3\n",
+		},
+		{
+			name: "delete at end",
+			a:    numbers(10, nil),
+			b:    numbers(9, nil),
+			want: "@@ -7,4 +7,3 @@\n 7\n 8\n 9\n-10\n",
+		},
+		{
+			name: "from empty",
+			a:    "",
+			b:    numbers(3, nil),
+			want: "@@ -0,0 +1,3 @@\n+1\n+2\n+3\n",
+		},
+		{
+			name: "to empty",
+			a:    numbers(3, nil),
+			b:    "",
+			want: "@@ -1,3 +0,0 @@\n-1\n-2\n-3\n",
+		},
+		{
+			name: "delete and insert",
+			a:    "a\nb\nc\n",
+			b:    "b\nc\nd\n",
+			want: "@@ -1,3 +1,3 @@\n-a\n b\n c\n+d\n",
+		},
+		{
+			name: "close changes in one hunk",
+			a:    numbers(20, nil),
+			b:    numbers(20, map[int]string{8: "eight", 14: "fourteen"}),
+			want: "@@ -5,13 +5,13 @@\n 5\n 6\n 7\n-8\n+eight\n 9\n 10\n 11\n 12\n 13\n-14\n+fourteen\n 15\n 16\n 17\n",
+		},
+		{
+			name: "far changes in two hunks",
+			a:    numbers(20, nil),
+			b:    numbers(20, map[int]string{8: "eight", 16: "sixteen"}),
+			want: "@@ -5,7 +5,7 @@\n 5\n 6\n 7\n-8\n+eight\n 9\n 10\n 11\n" +
+				"@@ -13,7 +13,7 @@\n 13\n 14\n 15\n-16\n+sixteen\n 17\n 18\n 19\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			want := c.want
+			if want != "" {
+				want = "--- a\n+++ b\n" + want
+			}
+			if got := Unified("a", "b", c.a, c.b); got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
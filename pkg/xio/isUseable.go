@@ -49,6 +49,8 @@ func checkArch(path string) (ok bool, exist bool) {
 func IsUseable(path string) bool {
 	path = filepath.Base(path)
 	path = path[:len(path)-len(filepath.Ext(path))]
+	// Test files are can be platform or architecture specific too.
+	path = strings.TrimSuffix(path, x.TestSuffix)
 	index := strings.LastIndexByte(path, '_')
 	if index == -1 {
 		return true
@@ -61,3 +63,23 @@ func IsUseable(path string) bool {
 	ok, _ = checkArch(path)
 	return ok
 }
+
+// IsTest reports path is test file or not.
+func IsTest(path string) bool {
+	path = filepath.Base(path)
+	path = path[:len(path)-len(filepath.Ext(path))]
+	return strings.HasSuffix(path, x.TestSuffix)
+}
+
+// IsInProject reports path is in project and not in standard library.
+func IsInProject(path string) bool {
+	path, err := filepath.Abs(path)
+	if err != nil || x.ProjectPath == "" {
+		return false
+	}
+	in := func(dir string) bool {
+		rel, err := filepath.Rel(dir, path)
+		return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+	}
+	return in(x.ProjectPath) && !in(x.StdlibPath)
+}
@@ -8,18 +8,27 @@ import (
 	"github.com/the-xlang/xxc/pkg/x"
 )
 
+// Overlay is the contents of files by absolute paths.
+// Openfx returns content of overlay instead of content of file if exist,
+// so changes of files can be checked without writing them.
+var Overlay = map[string]string{}
+
 // Openfx returns X source file.
 func Openfx(path string) (*File, error) {
 	path, _ = filepath.Abs(path)
 	if filepath.Ext(path) != x.SrcExt {
 		return nil, errors.New(x.GetError("file_not_x", path))
 	}
-	bytes, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
+	content, ok := Overlay[path]
+	if !ok {
+		bytes, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		content = string(bytes)
 	}
 	f := new(File)
 	f.Dir, f.Name = filepath.Split(path)
-	f.Data = []rune(string(bytes))
+	f.Data = []rune(content)
 	return f, nil
 }
@@ -0,0 +1,52 @@
+package xio
+
+import (
+	"io/fs"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/the-xlang/xxc/pkg/x"
+)
+
+// PackageFile returns path of first useable source file of directory.
+// Returns empty string if directory is not a package.
+func PackageFile(dir string) (string, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, info := range infos {
+		name := info.Name()
+		if !info.IsDir() && strings.HasSuffix(name, x.SrcExt) && IsUseable(name) {
+			return filepath.Join(dir, name), nil
+		}
+	}
+	return "", nil
+}
+
+// Packages returns path of first useable source file of each package
+// in root directory, root is included. Hidden directories and skipped
+// directories are not walked.
+func Packages(root string, skip ...string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(dir string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		if dir != root && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		for _, path := range skip {
+			if dir == path {
+				return filepath.SkipDir
+			}
+		}
+		file, err := PackageFile(dir)
+		if file != "" {
+			files = append(files, file)
+		}
+		return err
+	})
+	return files, err
+}
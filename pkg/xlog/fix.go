@@ -0,0 +1,178 @@
+package xlog
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"strings"
+)
+
+const fixMark = "fix:"
+
+// Edit is a text edit of source file.
+// Replaces range from Row:Column to EndRow:EndColumn with Text.
+// End of range is exclusive, range is empty for insertions.
+// Columns are same as columns of logs.
+type Edit struct {
+	Path      string
+	Row       int
+	Column    int
+	EndRow    int
+	EndColumn int
+	Text      string
+}
+
+// Fix is a suggested fix of log.
+type Fix struct {
+	Message string
+	Edits   []Edit
+}
+
+// FixedSource is a source file changed by fixes.
+type FixedSource struct {
+	Path string
+	Old  string
+	New  string
+}
+
+// before reports end of e is not after start of other.
+func (e *Edit) before(other *Edit) bool {
+	return e.EndRow < other.Row ||
+		e.EndRow == other.Row && e.EndColumn <= other.Column
+}
+
+func (e *Edit) empty() bool { return e.Row == e.EndRow && e.Column == e.EndColumn }
+
+// overlaps reports e and other are changes same text.
+// Insertions are not overlaps with each other, they are applied by order.
+func (e *Edit) overlaps(other *Edit) bool {
+	if e.empty() && other.empty() {
+		return false
+	}
+	return !e.before(other) && !other.before(e)
+}
+
+// columnOffset returns byte offset of column in line.
+// Offset is length of line if column is after of line.
+func columnOffset(line string, column int) int {
+	current := 1
+	for i, r := range line {
+		if current >= column || r == '\r' {
+			return i
+		}
+		if r == '\t' {
+			current += tabWidth
+		} else {
+			current += len(string(r))
+		}
+	}
+	return len(line)
+}
+
+// offset returns byte offset of position in source.
+// Offset is length of source if row is after of source.
+func offset(src string, row, column int) int {
+	start := 0
+	for ; row > 1; row-- {
+		i := strings.IndexByte(src[start:], '\n')
+		if i == -1 {
+			return len(src)
+		}
+		start += i + 1
+	}
+	line := src[start:]
+	if i := strings.IndexByte(line, '\n'); i != -1 {
+		line = line[:i]
+	}
+	return start + columnOffset(line, column)
+}
+
+// ApplyEdits returns source with edits.
+// Edits must not overlap, insertions at same position are applied by order.
+func ApplyEdits(src string, edits []Edit) (string, error) {
+	order := make([]int, len(edits))
+	for i := range order {
+		order[i] = i
+	}
+	// Edits are applied from end to keep offsets of others.
+	// Replacements are applied before insertions at same position,
+	// and latter insertions are applied before to keep order of them.
+	sort.Slice(order, func(i, j int) bool {
+		a, b := &edits[order[i]], &edits[order[j]]
+		switch {
+		case a.Row != b.Row:
+			return a.Row > b.Row
+		case a.Column != b.Column:
+			return a.Column > b.Column
+		case a.empty() != b.empty():
+			return !a.empty()
+		}
+		return order[i] > order[j]
+	})
+	for i, j := range order {
+		edit := &edits[j]
+		if i > 0 && edit.overlaps(&edits[order[i-1]]) {
+			return "", errors.New("overlapping edits")
+		}
+		start := offset(src, edit.Row, edit.Column)
+		end := offset(src, edit.EndRow, edit.EndColumn)
+		if end < start {
+			return "", errors.New("invalid range of edit")
+		}
+		src = src[:start] + edit.Text + src[end:]
+	}
+	return src, nil
+}
+
+// ApplyFixes applies the first fixes of logs to source files.
+// Fixes overlapping with previously applied fixes are skipped.
+// Sources are returned by order of first fixes of them.
+func ApplyFixes(logs []CompilerLog) ([]FixedSource, error) {
+	var paths []string
+	edits := map[string][]Edit{}
+	for _, log := range logs {
+		if len(log.Fixes) == 0 {
+			continue
+		}
+		fix := log.Fixes[0]
+		if conflicts(edits, fix.Edits) {
+			continue
+		}
+		for _, edit := range fix.Edits {
+			if _, ok := edits[edit.Path]; !ok {
+				paths = append(paths, edit.Path)
+			}
+			edits[edit.Path] = append(edits[edit.Path], edit)
+		}
+	}
+	sources := make([]FixedSource, 0, len(paths))
+	for _, path := range paths {
+		bytes, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		source := FixedSource{Path: path, Old: string(bytes)}
+		source.New, err = ApplyEdits(source.Old, edits[path])
+		if err != nil {
+			return nil, errors.New(path + ": " + err.Error())
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+func conflicts(edits map[string][]Edit, fix []Edit) bool {
+	for i := range fix {
+		for j, edit := range fix {
+			if j != i && edit.Path == fix[i].Path && edit.overlaps(&fix[i]) {
+				return true
+			}
+		}
+		for _, edit := range edits[fix[i].Path] {
+			if edit.overlaps(&fix[i]) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package xlog
+
+import "testing"
+
+func TestApplyEdits(t *testing.T) {
+	// Edit of range which is in one row.
+	edit := func(row, column, endColumn int, text string) Edit {
+		return Edit{Row: row, Column: column, EndRow: row, EndColumn: endColumn, Text: text}
+	}
+	cases := []struct {
+		name  string
+		src   string
+		edits []Edit
+		want  string
+		// Edits are invalid and error is expected.
+		err bool
+	}{
+		{
+			name:  "no edit",
+			src:   "main() {}\n",
+			edits: nil,
+			want:  "main() {}\n",
+		},
+		{
+			name:  "replace",
+			src:   "total: = 1\n",
+			edits: []Edit{edit(1, 1, 6, "count")},
+			want:  "count: = 1\n",
+		},
+		{
+			name:  "insert",
+			src:   "hidden() {}\n",
+			edits: []Edit{edit(1, 1, 1, "pub ")},
+			want:  "pub hidden() {}\n",
+		},
+		{
+			name:  "delete",
+			src:   "a\nb\nc\n",
+			edits: []Edit{{Row: 2, Column: 1, EndRow: 3, EndColumn: 1}},
+			want:  "a\nc\n",
+		},
+		{
+			name:  "edits of rows in any order",
+			src:   "x: = 1\ny: = x\n",
+			edits: []Edit{edit(1, 1, 2, "first"), edit(2, 6, 7, "first")},
+			want:  "first: = 1\ny: = first\n",
+		},
+		{
+			name:  "edits of same row",
+			src:   "n: = n + n\n",
+			edits: []Edit{edit(1, 10, 11, "m"), edit(1, 1, 2, "m"), edit(1, 6, 7, "m")},
+			want:  "m: = m + m\n",
+		},
+		{
+			name:  "insertions at same position keep order",
+			src:   "f()\n",
+			edits: []Edit{edit(1, 1, 1, "a"), edit(1, 1, 1, "b")},
+			want:  "abf()\n",
+		},
+		{
+			name:  "replacement before insertion at same position",
+			src:   "old()\n",
+			edits: []Edit{edit(1, 1, 1, "pub "), edit(1, 1, 4, "new")},
+			want:  "pub new()\n",
+		},
+		{
+			name:  "tabs are four columns",
+			src:   "main() {\n\tx: = 1\n}\n",
+			edits: []Edit{edit(2, 5, 6, "y")},
+			want:  "main() {\n\ty: = 1\n}\n",
+		},
+		{
+			name:  "multibyte runes are as wide as bytes",
+			src:   "s: = \"çiçek\" + s2\n",
+			edits: []Edit{edit(1, 18, 20, "t")},
+			want:  "s: = \"çiçek\" + t\n",
+		},
+		{
+			name:  "carriage return is kept",
+			src:   "a: = 1\r\nb: = 2\r\n",
+			edits: []Edit{edit(1, 6, 100, "3")},
+			want:  "a: = 3\r\nb: = 2\r\n",
+		},
+		{
+			name:  "insert at end of source",
+			src:   "main() {}",
+			edits: []Edit{edit(2, 1, 1, "\n")},
+			want:  "main() {}\n",
+		},
+		{
+			name:  "overlapping edits",
+			src:   "abcdef\n",
+			edits: []Edit{edit(1, 1, 4, "x"), edit(1, 3, 5, "y")},
+			err:   true,
+		},
+		{
+			name:  "end before start",
+			src:   "abcdef\n",
+			edits: []Edit{edit(1, 4, 2, "x")},
+			err:   true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := ApplyEdits(c.src, c.edits)
+			switch {
+			case c.err && err == nil:
+				t.Fatalf("want error, got %q", got)
+			case !c.err && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case !c.err && got != c.want:
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package xlog
+
+import "encoding/json"
+
+type jsonLog struct {
+	Type    string `json:"type"`
+	Code    string `json:"code,omitempty"`
+	Key     string `json:"key,omitempty"`
+	Args    []any  `json:"args,omitempty"`
+	Message string `json:"message"`
+	Path    string `json:"path,omitempty"`
+	Row     int    `json:"row,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	// End column of range of log, exclusive.
+	EndColumn int `json:"end_column,omitempty"`
+	// Log is from C++ compiler and could not mapped to X source code.
+	Internal bool       `json:"internal,omitempty"`
+	Notes    []jsonNote `json:"notes,omitempty"`
+	Fixes    []jsonFix  `json:"fixes,omitempty"`
+}
+
+type jsonFix struct {
+	Message string     `json:"message"`
+	Edits   []jsonEdit `json:"edits"`
+}
+
+// Range of edit is from row:column to end_row:end_column, end is exclusive.
+type jsonEdit struct {
+	Path      string `json:"path"`
+	Row       int    `json:"row"`
+	Column    int    `json:"column"`
+	EndRow    int    `json:"end_row"`
+	EndColumn int    `json:"end_column"`
+	Text      string `json:"text"`
+}
+
+func toJsonFix(fix *Fix) jsonFix {
+	jfix := jsonFix{
+		Message: fix.Message,
+		Edits:   make([]jsonEdit, len(fix.Edits)),
+	}
+	for i, edit := range fix.Edits {
+		jfix.Edits[i] = jsonEdit(edit)
+	}
+	return jfix
+}
+
+type jsonNote struct {
+	Message   string `json:"message"`
+	Path      string `json:"path,omitempty"`
+	Row       int    `json:"row,omitempty"`
+	Column    int    `json:"column,omitempty"`
+	EndColumn int    `json:"end_column,omitempty"`
+}
+
+func toJsonNote(n *Note) jsonNote {
+	jnote := jsonNote{
+		Message: n.Message,
+		Path:    n.Path,
+		Row:     n.Row,
+		Column:  n.Column,
+	}
+	if n.Length > 0 {
+		jnote.EndColumn = n.Column + n.Length
+	}
+	return jnote
+}
+
+func typeString(clog *CompilerLog) string {
+	if clog.IsError() {
+		return "error"
+	}
+	return "warning"
+}
+
+func toJsonLog(clog *CompilerLog) jsonLog {
+	jlog := jsonLog{
+		Type:     typeString(clog),
+		Code:     clog.Code(),
+		Key:      clog.Key,
+		Args:     clog.Args,
+		Message:  clog.Message,
+		Internal: clog.Internal,
+	}
+	if !clog.IsFlat() {
+		jlog.Path = clog.Path
+		jlog.Row = clog.Row
+		jlog.Column = clog.Column
+		if clog.Length > 0 {
+			jlog.EndColumn = clog.Column + clog.Length
+		}
+	}
+	for i := range clog.Notes {
+		jlog.Notes = append(jlog.Notes, toJsonNote(&clog.Notes[i]))
+	}
+	for i := range clog.Fixes {
+		jlog.Fixes = append(jlog.Fixes, toJsonFix(&clog.Fixes[i]))
+	}
+	return jlog
+}
+
+// JSON returns logs as JSON array.
+func JSON(logs []CompilerLog) (string, error) {
+	jlogs := make([]jsonLog, len(logs))
+	for i := range logs {
+		jlogs[i] = toJsonLog(&logs[i])
+	}
+	bytes, err := json.MarshalIndent(jlogs, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
@@ -0,0 +1,98 @@
+package xlog
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// Logs of every kind of position and source.
+var formatLogs = []CompilerLog{
+	{
+		Type: Error, Path: "/p/main.xx", Row: 3, Column: 5, Length: 4,
+		Key: "exist_id", Args: []any{"main"}, Message: "identifier is already exist: main",
+	},
+	{Type: FlatWarning, Key: "exist_undefined_doc", Message: "exist undefined documentation"},
+	// Log of C++ compiler has not key.
+	{Type: Warning, Path: "x.cpp", Row: 7, Column: 2, Message: "unused variable", Internal: true},
+}
+
+func TestJSON(t *testing.T) {
+	text, err := JSON(formatLogs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []map[string]any
+	if err := json.Unmarshal([]byte(text), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]any{
+		{
+			"type": "error", "code": "X0007", "key": "exist_id", "args": []any{"main"},
+			"message": "identifier is already exist: main",
+			"path":    "/p/main.xx", "row": 3.0, "column": 5.0, "end_column": 9.0,
+		},
+		{"type": "warning", "code": "W0002", "key": "exist_undefined_doc", "message": "exist undefined documentation"},
+		{"type": "warning", "message": "unused variable", "path": "x.cpp", "row": 7.0, "column": 2.0, "internal": true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestSARIF(t *testing.T) {
+	logs := append(formatLogs, formatLogs[0])
+	text, err := SARIF(logs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got sarifLog
+	if err := json.Unmarshal([]byte(text), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != sarifVersion || len(got.Runs) != 1 {
+		t.Fatalf("invalid log: %+v", got)
+	}
+	run := got.Runs[0]
+	// Rules are not repeated and logs without code are not rules.
+	rules := []sarifRule{{Id: "X0007", Name: "exist_id"}, {Id: "W0002", Name: "exist_undefined_doc"}}
+	if !reflect.DeepEqual(run.Tool.Driver.Rules, rules) {
+		t.Errorf("rules are %+v, want %+v", run.Tool.Driver.Rules, rules)
+	}
+	cases := []struct {
+		ruleId string
+		level  string
+		uri    string
+		region sarifRegion
+		props  sarifProps
+	}{
+		{"X0007", "error", "file:///p/main.xx", sarifRegion{StartLine: 3, StartColumn: 5, EndColumn: 9}, sarifProps{Key: "exist_id", Args: []any{"main"}}},
+		{"W0002", "warning", "", sarifRegion{}, sarifProps{Key: "exist_undefined_doc"}},
+		{"", "warning", "x.cpp", sarifRegion{StartLine: 7, StartColumn: 2}, sarifProps{Internal: true}},
+	}
+	if len(run.Results) != len(logs) {
+		t.Fatalf("got %d results, want %d", len(run.Results), len(logs))
+	}
+	for i, c := range cases {
+		r := run.Results[i]
+		if r.RuleId != c.ruleId || r.Level != c.level || r.Message.Text != logs[i].Message {
+			t.Errorf("result %d: got %+v", i, r)
+		}
+		if r.Properties == nil || !reflect.DeepEqual(*r.Properties, c.props) {
+			t.Errorf("result %d: properties are %+v, want %+v", i, r.Properties, c.props)
+		}
+		if c.uri == "" {
+			if len(r.Locations) > 0 {
+				t.Errorf("result %d: flat log has locations", i)
+			}
+			continue
+		}
+		if len(r.Locations) != 1 {
+			t.Fatalf("result %d: got %d locations", i, len(r.Locations))
+		}
+		loc := r.Locations[0].PhysicalLocation
+		if loc.ArtifactLocation.Uri != c.uri || loc.Region != c.region {
+			t.Errorf("result %d: location is %+v, want %s %+v", i, *loc, c.uri, c.region)
+		}
+	}
+}
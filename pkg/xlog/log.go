@@ -3,6 +3,8 @@ package xlog
 import (
 	"fmt"
 	"strings"
+
+	"github.com/the-xlang/xxc/pkg/x"
 )
 
 // Log types.
@@ -14,60 +16,160 @@ const (
 )
 
 const warningMark = "<!>"
+const internalMark = "internal:"
+const noteMark = "note:"
 
-// CompilerLog is a compiler log.
-type CompilerLog struct {
-	Type    uint8
+// Note is a related location of log such as previous declaration.
+// Path is empty if note has not location such as suggestions.
+type Note struct {
 	Row     int
 	Column  int
+	Length  int
 	Path    string
 	Message string
 }
 
-func (clog *CompilerLog) flatError() string {
-	return clog.Message
+// CompilerLog is a compiler log.
+type CompilerLog struct {
+	Type   uint8
+	Row    int
+	Column int
+	// Length is the column count of range of log, zero if unknown.
+	Length  int
+	Path    string
+	Message string
+	// Key is the message key of log.
+	// Empty if message is not from errors or warnings of language.
+	Key  string
+	Args []any
+	// Internal reports log is from C++ compiler and
+	// could not mapped to X source code.
+	Internal bool
+	// Notes are related locations of log.
+	Notes []Note
+	// Fixes are suggested edits of source files for log.
+	Fixes []Fix
 }
 
-func (clog *CompilerLog) error() string {
-	var log strings.Builder
+// IsError reports log is error or not.
+func (clog *CompilerLog) IsError() bool {
+	return clog.Type == FlatError || clog.Type == Error
+}
+
+// IsFlat reports log is not have position or not.
+func (clog *CompilerLog) IsFlat() bool {
+	return clog.Type == FlatError || clog.Type == FlatWarning
+}
+
+// Code returns stable code of log.
+// Returns empty string if log has not key.
+func (clog *CompilerLog) Code() string {
+	if clog.IsError() {
+		// Warnings may be reported as errors.
+		if code, ok := x.ErrorCodes[clog.Key]; ok {
+			return code
+		}
+	}
+	return x.WarningCodes[clog.Key]
+}
+
+// message returns message of log with code of log if exist,
+// and with internal mark if log is internal.
+func (clog *CompilerLog) message() string {
+	var msg strings.Builder
+	if code := clog.Code(); code != "" {
+		msg.WriteString(code)
+		msg.WriteString(": ")
+	}
+	if clog.Internal {
+		msg.WriteString(internalMark)
+		msg.WriteByte(' ')
+	}
+	msg.WriteString(clog.Message)
+	return msg.String()
+}
+
+// position writes position of log.
+// Column is omitted if unknown.
+func (clog *CompilerLog) position(log *strings.Builder) {
 	log.WriteString(clog.Path)
 	log.WriteByte(':')
 	log.WriteString(fmt.Sprint(clog.Row))
-	log.WriteByte(':')
-	log.WriteString(fmt.Sprint(clog.Column))
+	if clog.Column > 0 {
+		log.WriteByte(':')
+		log.WriteString(fmt.Sprint(clog.Column))
+	}
+}
+
+func (clog *CompilerLog) flatError() string {
+	return clog.message()
+}
+
+func (clog *CompilerLog) error() string {
+	var log strings.Builder
+	clog.position(&log)
 	log.WriteByte(' ')
-	log.WriteString(clog.Message)
+	log.WriteString(clog.message())
 	return log.String()
 }
 
 func (clog *CompilerLog) flatWarning() string {
-	return warningMark + " " + clog.Message
+	return warningMark + " " + clog.message()
 }
 
 func (clog *CompilerLog) warning() string {
 	var log strings.Builder
 	log.WriteString(warningMark)
 	log.WriteByte(' ')
-	log.WriteString(clog.Path)
-	log.WriteByte(':')
-	log.WriteString(fmt.Sprint(clog.Row))
-	log.WriteByte(':')
-	log.WriteString(fmt.Sprint(clog.Column))
+	clog.position(&log)
 	log.WriteByte(' ')
-	log.WriteString(clog.Message)
+	log.WriteString(clog.message())
+	return log.String()
+}
+
+// log returns note as log to print it like logs.
+func (n *Note) log() CompilerLog {
+	return CompilerLog{
+		Row:     n.Row,
+		Column:  n.Column,
+		Length:  n.Length,
+		Path:    n.Path,
+		Message: n.Message,
+	}
+}
+
+func (clog *CompilerLog) notes() string {
+	var log strings.Builder
+	for _, n := range clog.Notes {
+		nlog := n.log()
+		log.WriteString("\n  ")
+		log.WriteString(noteMark)
+		log.WriteByte(' ')
+		if n.Path != "" {
+			nlog.position(&log)
+			log.WriteByte(' ')
+		}
+		log.WriteString(n.Message)
+	}
+	for _, fix := range clog.Fixes {
+		log.WriteString("\n  ")
+		log.WriteString(fixMark)
+		log.WriteByte(' ')
+		log.WriteString(fix.Message)
+	}
 	return log.String()
 }
 
 func (clog CompilerLog) String() string {
 	switch clog.Type {
 	case FlatError:
-		return clog.flatError()
+		return clog.flatError() + clog.notes()
 	case Error:
-		return clog.error()
+		return clog.error() + clog.notes()
 	case FlatWarning:
-		return clog.flatWarning()
+		return clog.flatWarning() + clog.notes()
 	case Warning:
-		return clog.warning()
+		return clog.warning() + clog.notes()
 	}
 	return ""
 }
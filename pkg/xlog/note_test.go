@@ -0,0 +1,86 @@
+package xlog
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/the-xlang/xxc/pkg/xio"
+)
+
+// Log with located and unlocated notes.
+var noteLog = CompilerLog{
+	Type: Error, Path: "/p/main.xx", Row: 3, Column: 1, Length: 1,
+	Key: "exist_id", Args: []any{"f"}, Message: "identifier is already exist: f",
+	Notes: []Note{
+		{Path: "/p/other.xx", Row: 1, Column: 1, Length: 1, Message: "previously declared here"},
+		{Message: "did you mean g?"},
+	},
+}
+
+func TestNotesText(t *testing.T) {
+	defer delete(xio.Overlay, "/p/main.xx")
+	defer delete(xio.Overlay, "/p/other.xx")
+	xio.Overlay["/p/main.xx"] = "g() {}\n\nf() {}\n"
+	xio.Overlay["/p/other.xx"] = "f() {}\n"
+	want := "/p/main.xx:3:1 X0007: identifier is already exist: f" +
+		"\n  note: /p/other.xx:1:1 previously declared here" +
+		"\n  note: did you mean g?"
+	if got := noteLog.String(); got != want {
+		t.Errorf("String:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	want = "/p/main.xx:3:1 X0007: identifier is already exist: f" +
+		"\n 3 | f() {}\n   | ^" +
+		"\n  note: /p/other.xx:1:1 previously declared here" +
+		"\n 1 | f() {}\n   | ^" +
+		"\n  note: did you mean g?"
+	if got := noteLog.Pretty(false); got != want {
+		t.Errorf("Pretty:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestNotesJSON(t *testing.T) {
+	text, err := JSON([]CompilerLog{noteLog})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []jsonLog
+	if err := json.Unmarshal([]byte(text), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []jsonNote{
+		{Message: "previously declared here", Path: "/p/other.xx", Row: 1, Column: 1, EndColumn: 2},
+		{Message: "did you mean g?"},
+	}
+	if len(got) != 1 || !reflect.DeepEqual(got[0].Notes, want) {
+		t.Errorf("got %+v, want notes %+v", got, want)
+	}
+}
+
+func TestNotesSARIF(t *testing.T) {
+	text, err := SARIF([]CompilerLog{noteLog})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got sarifLog
+	if err := json.Unmarshal([]byte(text), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []sarifLocation{
+		{
+			Id:      1,
+			Message: &sarifMessage{Text: "previously declared here"},
+			PhysicalLocation: &sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{Uri: "file:///p/other.xx"},
+				Region:           sarifRegion{StartLine: 1, StartColumn: 1, EndColumn: 2},
+			},
+		},
+		{Id: 2, Message: &sarifMessage{Text: "did you mean g?"}},
+	}
+	if len(got.Runs) != 1 || len(got.Runs[0].Results) != 1 {
+		t.Fatalf("invalid log: %+v", got)
+	}
+	if related := got.Runs[0].Results[0].RelatedLocations; !reflect.DeepEqual(related, want) {
+		t.Errorf("got %+v, want %+v", related, want)
+	}
+}
@@ -0,0 +1,211 @@
+package xlog
+
+import (
+	"encoding/json"
+	"net/url"
+	"path/filepath"
+
+	"github.com/the-xlang/xxc/pkg/x"
+)
+
+// SARIF version of outputs.
+const sarifVersion = "2.1.0"
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version"`
+	Rules   []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleId    string          `json:"ruleId,omitempty"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+	// Notes of log.
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+	Fixes            []sarifFix      `json:"fixes,omitempty"`
+	Properties       *sarifProps     `json:"properties,omitempty"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion           `json:"deletedRegion"`
+	InsertedContent *sarifArtifactContent `json:"insertedContent,omitempty"`
+}
+
+type sarifArtifactContent struct {
+	Text string `json:"text"`
+}
+
+type sarifProps struct {
+	Key      string `json:"key,omitempty"`
+	Args     []any  `json:"args,omitempty"`
+	Internal bool   `json:"internal,omitempty"`
+}
+
+type sarifLocation struct {
+	Id               int                    `json:"id,omitempty"`
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	Message          *sarifMessage          `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	Uri string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+func sarifUri(path string) string {
+	uri := url.URL{Path: filepath.ToSlash(path)}
+	if filepath.IsAbs(path) {
+		uri.Scheme = "file"
+	}
+	return uri.String()
+}
+
+func toSarifPhysicalLocation(path string, row, column, length int) *sarifPhysicalLocation {
+	region := sarifRegion{StartLine: row, StartColumn: column}
+	if length > 0 {
+		region.EndColumn = column + length
+	}
+	return &sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{Uri: sarifUri(path)},
+		Region:           region,
+	}
+}
+
+// toSarifFix returns fix with changes of artifacts by order of edits.
+func toSarifFix(fix *Fix) sarifFix {
+	sfix := sarifFix{Description: sarifMessage{Text: fix.Message}}
+	changes := map[string]int{}
+	for _, edit := range fix.Edits {
+		i, ok := changes[edit.Path]
+		if !ok {
+			i = len(sfix.ArtifactChanges)
+			changes[edit.Path] = i
+			sfix.ArtifactChanges = append(sfix.ArtifactChanges, sarifArtifactChange{
+				ArtifactLocation: sarifArtifactLocation{Uri: sarifUri(edit.Path)},
+			})
+		}
+		replacement := sarifReplacement{DeletedRegion: sarifRegion{
+			StartLine:   edit.Row,
+			StartColumn: edit.Column,
+			EndLine:     edit.EndRow,
+			EndColumn:   edit.EndColumn,
+		}}
+		if edit.Text != "" {
+			replacement.InsertedContent = &sarifArtifactContent{Text: edit.Text}
+		}
+		change := &sfix.ArtifactChanges[i]
+		change.Replacements = append(change.Replacements, replacement)
+	}
+	return sfix
+}
+
+func toSarifResult(clog *CompilerLog) sarifResult {
+	result := sarifResult{
+		RuleId:  clog.Code(),
+		Level:   typeString(clog),
+		Message: sarifMessage{Text: clog.Message},
+	}
+	if clog.Key != "" || clog.Internal {
+		result.Properties = &sarifProps{Key: clog.Key, Args: clog.Args, Internal: clog.Internal}
+	}
+	if !clog.IsFlat() {
+		result.Locations = []sarifLocation{{
+			PhysicalLocation: toSarifPhysicalLocation(clog.Path, clog.Row, clog.Column, clog.Length),
+		}}
+	}
+	for i, n := range clog.Notes {
+		location := sarifLocation{
+			// Ids of related locations are starts at 1.
+			Id:      i + 1,
+			Message: &sarifMessage{Text: n.Message},
+		}
+		if n.Path != "" {
+			location.PhysicalLocation = toSarifPhysicalLocation(n.Path, n.Row, n.Column, n.Length)
+		}
+		result.RelatedLocations = append(result.RelatedLocations, location)
+	}
+	for i := range clog.Fixes {
+		result.Fixes = append(result.Fixes, toSarifFix(&clog.Fixes[i]))
+	}
+	return result
+}
+
+// SARIF returns logs as SARIF log.
+func SARIF(logs []CompilerLog) (string, error) {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:    "xxc",
+			Version: x.Version,
+		}},
+		Results: make([]sarifResult, len(logs)),
+	}
+	rules := map[string]bool{}
+	for i := range logs {
+		clog := &logs[i]
+		run.Results[i] = toSarifResult(clog)
+		code := clog.Code()
+		if code == "" || rules[code] {
+			continue
+		}
+		rules[code] = true
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{Id: code, Name: clog.Key})
+	}
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	}
+	bytes, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
@@ -0,0 +1,174 @@
+package xlog
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/the-xlang/xxc/pkg/xio"
+)
+
+// Width of tab in columns of logs.
+const tabWidth = 4
+
+// ANSI escape codes of colors.
+const (
+	colorReset  = "\x1b[0m"
+	colorBold   = "\x1b[1m"
+	colorRed    = "\x1b[1;31m"
+	colorYellow = "\x1b[1;33m"
+	colorBlue   = "\x1b[1;34m"
+	colorCyan   = "\x1b[1;36m"
+	colorGreen  = "\x1b[1;32m"
+)
+
+// Lines of source files by path, nil if file is not readable.
+var sources = map[string][]string{}
+
+// sourceLine returns line of file at row.
+// Content of overlay is used instead of file if exist.
+// Reports false if line is not exist.
+func sourceLine(path string, row int) (string, bool) {
+	lines, ok := sources[path]
+	if text, overlay := xio.Overlay[path]; overlay {
+		lines = strings.Split(text, "\n")
+	} else if !ok {
+		bytes, err := os.ReadFile(path)
+		if err == nil {
+			lines = strings.Split(string(bytes), "\n")
+		}
+		sources[path] = lines
+	}
+	if row < 1 || row > len(lines) {
+		return "", false
+	}
+	return strings.TrimRight(lines[row-1], "\r"), true
+}
+
+// painter writes colored texts if enabled.
+type painter struct {
+	sb    strings.Builder
+	color bool
+}
+
+func (p *painter) paint(color, text string) {
+	if p.color && text != "" {
+		p.sb.WriteString(color)
+		p.sb.WriteString(text)
+		p.sb.WriteString(colorReset)
+		return
+	}
+	p.sb.WriteString(text)
+}
+
+func (clog *CompilerLog) severityColor() string {
+	if clog.IsError() {
+		return colorRed
+	}
+	return colorYellow
+}
+
+// snippet writes source line of log with markers under the range of log.
+// Markers are painted with color.
+// Columns are same as lexer, tabs are wide as tabWidth and others are
+// wide as bytes of UTF-8. Tabs are expanded to spaces for alignment.
+func (clog *CompilerLog) snippet(p *painter, color string) {
+	line, ok := sourceLine(clog.Path, clog.Row)
+	if !ok || strings.TrimSpace(line) == "" {
+		return
+	}
+	gutter := fmt.Sprint(clog.Row)
+	blank := strings.Repeat(" ", len(gutter))
+	var src, marker strings.Builder
+	start := clog.Column
+	end := start + clog.Length
+	if end <= start {
+		end = start + 1
+	}
+	column := 1
+	for _, r := range line {
+		text := string(r)
+		width := len(text)
+		if r == '\t' {
+			text = strings.Repeat(" ", tabWidth)
+			width = tabWidth
+		}
+		src.WriteString(text)
+		// Marker is written with display width of text.
+		mark := " "
+		switch {
+		case column == start:
+			mark = "^"
+		case column > start && column < end:
+			mark = "~"
+		}
+		marker.WriteString(strings.Repeat(mark, len([]rune(text))))
+		column += width
+	}
+	// Position may be after of last rune such as missing tokens at end of line.
+	if column == start {
+		marker.WriteByte('^')
+	}
+	p.sb.WriteByte('\n')
+	p.paint(colorBlue, " "+gutter+" |")
+	p.sb.WriteByte(' ')
+	p.sb.WriteString(src.String())
+	if start < 1 {
+		return
+	}
+	p.sb.WriteByte('\n')
+	p.paint(colorBlue, " "+blank+" |")
+	p.sb.WriteByte(' ')
+	mark := strings.TrimRight(marker.String(), " ")
+	indent := len(mark) - len(strings.TrimLeft(mark, " "))
+	p.sb.WriteString(mark[:indent])
+	p.paint(color, mark[indent:])
+}
+
+// note writes note with source snippet.
+func (p *painter) note(n *Note) {
+	p.sb.WriteString("\n  ")
+	p.paint(colorCyan, noteMark)
+	p.sb.WriteByte(' ')
+	if n.Path == "" {
+		p.sb.WriteString(n.Message)
+		return
+	}
+	nlog := n.log()
+	var position strings.Builder
+	nlog.position(&position)
+	p.paint(colorBold, position.String())
+	p.sb.WriteByte(' ')
+	p.sb.WriteString(n.Message)
+	nlog.snippet(p, colorCyan)
+}
+
+// Pretty returns human readable log with source snippet.
+// Color enables ANSI colors.
+func (clog CompilerLog) Pretty(color bool) string {
+	p := painter{color: color}
+	if !clog.IsError() {
+		p.paint(colorYellow, warningMark)
+		p.sb.WriteByte(' ')
+	}
+	if clog.IsFlat() {
+		p.paint(colorBold, clog.message())
+	} else {
+		var position strings.Builder
+		clog.position(&position)
+		p.paint(clog.severityColor(), position.String())
+		p.sb.WriteByte(' ')
+		p.paint(colorBold, clog.message())
+		clog.snippet(&p, clog.severityColor())
+	}
+	for i := range clog.Notes {
+		p.note(&clog.Notes[i])
+	}
+	for _, fix := range clog.Fixes {
+		p.sb.WriteString("\n  ")
+		p.paint(colorGreen, fixMark)
+		p.sb.WriteByte(' ')
+		p.sb.WriteString(fix.Message)
+	}
+	return p.sb.String()
+}
@@ -0,0 +1,87 @@
+package xlog
+
+import (
+	"testing"
+
+	"github.com/the-xlang/xxc/pkg/xio"
+)
+
+func TestPretty(t *testing.T) {
+	const path = "/p/main.xx"
+	defer delete(xio.Overlay, path)
+	xio.Overlay[path] = "main() {\r\n\tcount: = 1\n\tx: = \"çç\" + y\n\n\toutln(\n"
+	cases := []struct {
+		name  string
+		log   CompilerLog
+		color bool
+		want  string
+	}{
+		{
+			name: "range",
+			log:  CompilerLog{Type: Error, Path: path, Row: 1, Column: 1, Length: 4, Message: "msg"},
+			want: "/p/main.xx:1:1 msg\n 1 | main() {\n   | ^~~~",
+		},
+		{
+			name: "unknown length",
+			log:  CompilerLog{Type: Error, Path: path, Row: 1, Column: 5, Message: "msg"},
+			want: "/p/main.xx:1:5 msg\n 1 | main() {\n   |     ^",
+		},
+		{
+			name: "tab",
+			log:  CompilerLog{Type: Warning, Path: path, Row: 2, Column: 5, Length: 5, Message: "msg"},
+			want: "<!> /p/main.xx:2:5 msg\n 2 |     count: = 1\n   |     ^~~~~",
+		},
+		{
+			// Columns of multi-byte runes are wide as their bytes.
+			name: "multi-byte runes",
+			log:  CompilerLog{Type: Error, Path: path, Row: 3, Column: 19, Length: 1, Message: "msg"},
+			want: "/p/main.xx:3:19 msg\n 3 |     x: = \"çç\" + y\n   |                 ^",
+		},
+		{
+			name: "end of line",
+			log:  CompilerLog{Type: Error, Path: path, Row: 5, Column: 11, Message: "msg"},
+			want: "/p/main.xx:5:11 msg\n 5 |     outln(\n   |           ^",
+		},
+		{
+			name: "without column",
+			log:  CompilerLog{Type: Error, Path: path, Row: 1, Message: "msg"},
+			want: "/p/main.xx:1 msg\n 1 | main() {",
+		},
+		{
+			name: "empty line",
+			log:  CompilerLog{Type: Error, Path: path, Row: 4, Column: 1, Message: "msg"},
+			want: "/p/main.xx:4:1 msg",
+		},
+		{
+			name: "missing file",
+			log:  CompilerLog{Type: Error, Path: "/p/missing.xx", Row: 1, Column: 1, Message: "msg"},
+			want: "/p/missing.xx:1:1 msg",
+		},
+		{
+			name: "flat",
+			log:  CompilerLog{Type: FlatWarning, Message: "msg"},
+			want: "<!> msg",
+		},
+		{
+			name:  "color",
+			log:   CompilerLog{Type: Error, Path: path, Row: 1, Column: 1, Length: 4, Message: "msg"},
+			color: true,
+			want: colorRed + "/p/main.xx:1:1" + colorReset + " " + colorBold + "msg" + colorReset +
+				"\n" + colorBlue + " 1 |" + colorReset + " main() {" +
+				"\n" + colorBlue + "   |" + colorReset + " " + colorRed + "^~~~" + colorReset,
+		},
+		{
+			name:  "warning color",
+			log:   CompilerLog{Type: FlatWarning, Message: "msg"},
+			color: true,
+			want:  colorYellow + "<!>" + colorReset + " " + colorBold + "msg" + colorReset,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.log.Pretty(c.color); got != c.want {
+				t.Errorf("got:\n%q\nwant:\n%q", got, c.want)
+			}
+		})
+	}
+}
@@ -8,26 +8,46 @@ const (
 )
 
 type XSet struct {
-	CppOutDir    string   `json:"cpp_out_dir"`
-	CppOutName   string   `json:"cpp_out_name"`
-	OutName      string   `json:"out_name"`
-	Language     string   `json:"language"`
-	Mode         string   `json:"mode"`
-	PostCommands []string `json:"post_commands"`
-	Indent       string   `json:"indent"`
-	IndentCount  int      `json:"indent_count"`
+	CppOutDir        string   `json:"cpp_out_dir"`
+	CppOutName       string   `json:"cpp_out_name"`
+	OutName          string   `json:"out_name"`
+	Language         string   `json:"language"`
+	Mode             string   `json:"mode"`
+	PostCommands     []string `json:"post_commands"`
+	Indent           string   `json:"indent"`
+	IndentCount      int      `json:"indent_count"`
+	Compiler         string   `json:"compiler"`
+	CppStandard      string   `json:"cpp_standard"`
+	CompilerFlags    []string `json:"compiler_flags"`
+	IncludeDirs      []string `json:"include_dirs"`
+	LinkLibs         []string `json:"link_libs"`
+	Timestamp        bool     `json:"timestamp"`
+	LineDirectives   bool     `json:"line_directives"`
+	WarningsAsErrors bool     `json:"warnings_as_errors"`
+	DisabledWarnings []string `json:"disabled_warnings"`
+	MaxErrors        int      `json:"max_errors"`
 }
 
 // Default XSet instance.
 var Default = &XSet{
-	CppOutDir:    "./dist",
-	CppOutName:   "x.cpp",
-	OutName:      "main",
-	Language:     "",
-	Mode:         "transpile",
-	Indent:       "\t",
-	IndentCount:  1,
-	PostCommands: []string{},
+	CppOutDir:        "./dist",
+	CppOutName:       "x.cpp",
+	OutName:          "main",
+	Language:         "",
+	Mode:             "transpile",
+	Indent:           "\t",
+	IndentCount:      1,
+	PostCommands:     []string{},
+	Compiler:         "g++",
+	CppStandard:      "c++17",
+	CompilerFlags:    []string{},
+	IncludeDirs:      []string{},
+	LinkLibs:         []string{},
+	Timestamp:        true,
+	LineDirectives:   false,
+	WarningsAsErrors: false,
+	DisabledWarnings: []string{},
+	MaxErrors:        0,
 }
 
 // Load loads XSet from json string.
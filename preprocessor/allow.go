@@ -0,0 +1,107 @@
+package preprocessor
+
+import "github.com/the-xlang/xxc/ast/models"
+
+// Allow is a warning allowed by allow pragma directive.
+type Allow struct {
+	Key string
+	// Path of file which has directive.
+	Path string
+	// Rows of declaration, both of them are zero if allowed for whole file.
+	// End is -1 if declaration is the last one of file.
+	Start int
+	End   int
+}
+
+// Allows are the allowed warnings of files.
+type Allows []Allow
+
+// Has reports warning of key is allowed at row of file.
+// Path and row are empty for warnings without location,
+// these are allowed only if allowed for whole file.
+func (allows Allows) Has(key, path string, row int) bool {
+	for _, allow := range allows {
+		if allow.Key != key || path != "" && allow.Path != path {
+			continue
+		}
+		if allow.Start == 0 ||
+			row >= allow.Start && (allow.End == -1 || row <= allow.End) {
+			return true
+		}
+	}
+	return false
+}
+
+func allowKeys(obj models.Object) []string {
+	pp, ok := obj.Data.(models.Preprocessor)
+	if !ok {
+		return nil
+	}
+	d, ok := pp.Command.(models.Directive)
+	if !ok {
+		return nil
+	}
+	allow, ok := d.Command.(models.DirectiveAllow)
+	if !ok {
+		return nil
+	}
+	return allow.Keys
+}
+
+// isDecoration reports object is belongs to the next declaration.
+func isDecoration(obj models.Object) bool {
+	switch obj.Data.(type) {
+	case models.Comment, models.Preprocessor, models.Attribute, []models.GenericType:
+		return true
+	}
+	return false
+}
+
+// GetAllows returns warnings allowed by allow pragma directives of tree.
+// Directives at head of file, before anything except comments, use
+// declarations and other directives are allows warnings for whole file.
+// Others are allows warnings for the next declaration.
+func GetAllows(tree Tree) Allows {
+	var allows Allows
+	head := true
+	for i, obj := range tree {
+		keys := allowKeys(obj)
+		if keys == nil {
+			switch obj.Data.(type) {
+			case models.Comment, models.Preprocessor, models.Use:
+			default:
+				head = false
+			}
+			continue
+		}
+		start, end := 0, 0
+		if !head {
+			var ok bool
+			start, end, ok = declRows(tree[i+1:])
+			if !ok {
+				continue
+			}
+		}
+		path := obj.Tok.File.Path()
+		for _, key := range keys {
+			allows = append(allows, Allow{Key: key, Path: path, Start: start, End: end})
+		}
+	}
+	return allows
+}
+
+// declRows returns rows of the first declaration of tree.
+// Reports false if there is no declaration.
+func declRows(tree Tree) (start, end int, ok bool) {
+	i := 0
+	for i < len(tree) && isDecoration(tree[i]) {
+		i++
+	}
+	switch {
+	case i == len(tree):
+		return 0, 0, false
+	case i+1 == len(tree):
+		return tree[i].Tok.Row, -1, true
+	}
+	return tree[i].Tok.Row, tree[i+1].Tok.Row - 1, true
+}
@@ -0,0 +1,33 @@
+package preprocessor
+
+import "testing"
+
+func TestAllowsHas(t *testing.T) {
+	allows := Allows{
+		{Key: "doc_ignored", Path: "/a.xx", Start: 3, End: 5},
+		{Key: "doc_ignored", Path: "/b.xx", Start: 0, End: 0},
+		{Key: "exist_undefined_doc", Path: "/a.xx", Start: 7, End: -1},
+	}
+	cases := []struct {
+		key  string
+		path string
+		row  int
+		want bool
+	}{
+		{"doc_ignored", "/a.xx", 4, true},
+		{"doc_ignored", "/a.xx", 6, false},
+		// Rows of declaration of another file.
+		{"doc_ignored", "/c.xx", 4, false},
+		{"doc_ignored", "/b.xx", 100, true},
+		{"exist_undefined_doc", "/a.xx", 100, true},
+		{"exist_undefined_doc", "/b.xx", 100, false},
+		// Warnings without location.
+		{"doc_ignored", "", 0, true},
+		{"exist_undefined_doc", "", 0, false},
+	}
+	for _, c := range cases {
+		if got := allows.Has(c.key, c.path, c.row); got != c.want {
+			t.Errorf("Has(%q, %q, %d): got %v, want %v", c.key, c.path, c.row, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package refactor
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/the-xlang/xxc/parser"
+	"github.com/the-xlang/xxc/pkg/x"
+	"github.com/the-xlang/xxc/pkg/xio"
+	"github.com/the-xlang/xxc/pkg/xlog"
+)
+
+type Symbol = parser.Symbol
+
+// Location is the position of identifier in source file.
+// Row and column are same as compiler logs.
+type Location struct {
+	Path   string
+	Row    int
+	Column int
+}
+
+func (l Location) String() string {
+	return fmt.Sprintf("%s:%d:%d", l.Path, l.Row, l.Column)
+}
+
+func locationOf(tok parser.Tok) Location {
+	return Location{tok.File.Path(), tok.Row, tok.Column}
+}
+
+// key identifies definition across compilations.
+// Built-in definitions have not location,
+// they are identified by kind and identifier.
+type key struct {
+	Location
+	kind string
+	id   string
+}
+
+func keyOf(s Symbol) key {
+	if s.Tok.File == nil {
+		return key{kind: s.Kind, id: s.Id}
+	}
+	return key{Location: locationOf(s.Tok)}
+}
+
+// ref is a reference of identifier to definition.
+type ref struct {
+	id  string
+	def key
+}
+
+// project is the references of packages of project.
+type project struct {
+	// Paths of a source file of each package, package of cursor is first.
+	pkgs  []string
+	refs  map[Location]ref
+	links []parser.Link
+	// References of package of cursor for find symbol at cursor.
+	cursor *parser.Refs
+	// Parser of package of cursor for find definitions of package scope.
+	scope *parser.Parser
+}
+
+// packages returns path of a source file of each package in project.
+// Package of file is the first. Standard library and output directory
+// are not packages of project.
+//
+// Packages are compiled from first files like tests,
+// other files are parsed as local package.
+func packages(path string) ([]string, error) {
+	first, err := xio.PackageFile(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	if first == "" {
+		first = path
+	}
+	out, _ := filepath.Abs(x.Set.CppOutDir)
+	files, err := xio.Packages(x.ProjectPath, x.StdlibPath, out)
+	if err != nil {
+		return nil, err
+	}
+	pkgs := []string{first}
+	for _, file := range files {
+		if filepath.Dir(file) != filepath.Dir(path) {
+			pkgs = append(pkgs, file)
+		}
+	}
+	return pkgs, nil
+}
+
+// compile compiles packages and records references of them.
+// Returns logs of first package which has errors.
+func (pr *project) compile() []xlog.CompilerLog {
+	pr.refs = map[Location]ref{}
+	pr.links = nil
+	for i, path := range pr.pkgs {
+		// Used packages are may be changed, so they are parsed again.
+		parser.Reset()
+		f, err := xio.Openfx(path)
+		if err != nil {
+			return []xlog.CompilerLog{{Type: xlog.FlatError, Message: err.Error()}}
+		}
+		p := parser.New(f)
+		p.Refs = new(parser.Refs)
+		// Tests are may refer to definitions too.
+		p.Test = true
+		p.Parsef(false, false)
+		if len(p.Errors) > 0 {
+			return p.Errors
+		}
+		for _, r := range p.Refs.List() {
+			loc := locationOf(r.Tok)
+			if _, ok := pr.refs[loc]; !ok {
+				pr.refs[loc] = ref{id: r.Tok.Kind, def: keyOf(r.Symbol())}
+			}
+		}
+		pr.links = append(pr.links, p.Refs.Links()...)
+		if i == 0 {
+			pr.cursor = p.Refs
+			pr.scope = p
+		}
+	}
+	return nil
+}
+
+// group returns keys of definitions which must have same identifier with sym.
+func (pr *project) group(sym Symbol) map[key]bool {
+	group := map[key]bool{keyOf(sym): true}
+	for changed := true; changed; {
+		changed = false
+		for _, link := range pr.links {
+			a, b := key{Location: locationOf(link[0])}, key{Location: locationOf(link[1])}
+			if group[a] != group[b] {
+				group[a], group[b] = true, true
+				changed = true
+			}
+		}
+	}
+	return group
+}
+
+// locations returns sorted locations of references to definitions of group.
+func (pr *project) locations(group map[key]bool) []Location {
+	var locs []Location
+	for loc, r := range pr.refs {
+		if group[r.def] {
+			locs = append(locs, loc)
+		}
+	}
+	sortLocations(locs)
+	return locs
+}
+
+// sortLocations sorts locations by paths and positions.
+func sortLocations(locs []Location) {
+	sort.Slice(locs, func(i, j int) bool {
+		a, b := locs[i], locs[j]
+		switch {
+		case a.Path != b.Path:
+			return a.Path < b.Path
+		case a.Row != b.Row:
+			return a.Row < b.Row
+		}
+		return a.Column < b.Column
+	})
+}
+
+// find compiles project of file and returns symbol at position.
+func find(path string, row, column int) (*project, Symbol, []xlog.CompilerLog, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, Symbol{}, nil, err
+	}
+	pr := new(project)
+	pr.pkgs, err = packages(path)
+	if err != nil {
+		return nil, Symbol{}, nil, err
+	}
+	if logs := pr.compile(); logs != nil {
+		return nil, Symbol{}, logs, nil
+	}
+	r := pr.cursor.At(path, row, column)
+	if r == nil {
+		return nil, Symbol{}, nil, errors.New("no identifier found at " + Location{path, row, column}.String())
+	}
+	return pr, r.Symbol(), nil, nil
+}
+
+// Refs returns symbol of identifier at position of file and locations
+// of references to it in packages of project, including definitions.
+// Functions of traits and their implementations are the same symbol.
+// Returns logs if packages have errors.
+func Refs(path string, row, column int) (Symbol, []Location, []xlog.CompilerLog, error) {
+	pr, sym, logs, err := find(path, row, column)
+	if pr == nil {
+		return sym, nil, logs, err
+	}
+	return sym, pr.locations(pr.group(sym)), nil, nil
+}
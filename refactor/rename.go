@@ -0,0 +1,172 @@
+package refactor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/the-xlang/xxc/lex"
+	"github.com/the-xlang/xxc/lex/tokens"
+	"github.com/the-xlang/xxc/parser"
+	"github.com/the-xlang/xxc/pkg/xapi"
+	"github.com/the-xlang/xxc/pkg/xio"
+	"github.com/the-xlang/xxc/pkg/xlog"
+)
+
+// isId reports id is a valid identifier which is not keyword.
+func isId(id string) bool {
+	l := lex.NewLex(&xio.File{Data: []rune(id)})
+	toks := l.Lex()
+	return len(l.Logs) == 0 && len(toks) == 1 &&
+		toks[0].Id == tokens.Id && toks[0].Kind == id && !xapi.IsIgnoreId(id)
+}
+
+// renaming is the edits of renaming.
+type renaming struct {
+	// Columns of edits by paths and rows.
+	columns map[string]map[int][]int
+	delta   int
+}
+
+// shift returns location of loc after renaming.
+func (rn *renaming) shift(loc Location) Location {
+	shifted := loc
+	for _, column := range rn.columns[loc.Path][loc.Row] {
+		if column < loc.Column {
+			shifted.Column += rn.delta
+		}
+	}
+	return shifted
+}
+
+func (rn *renaming) shiftKey(k key) key {
+	if k.Path != "" {
+		k.Location = rn.shift(k.Location)
+	}
+	return k
+}
+
+// sources returns sources of files with renamed identifiers.
+func (rn *renaming) sources(old, id string) ([]xlog.FixedSource, error) {
+	paths := make([]string, 0, len(rn.columns))
+	for path := range rn.columns {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var sources []xlog.FixedSource
+	for _, path := range paths {
+		rows := rn.columns[path]
+		bytes, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var edits []xlog.Edit
+		for row, columns := range rows {
+			for _, column := range columns {
+				edits = append(edits, xlog.Edit{
+					Path:      path,
+					Row:       row,
+					Column:    column,
+					EndRow:    row,
+					EndColumn: column + len(old),
+					Text:      id,
+				})
+			}
+		}
+		source := xlog.FixedSource{Path: path, Old: string(bytes)}
+		source.New, err = xlog.ApplyEdits(source.Old, edits)
+		if err != nil {
+			return nil, errors.New(path + ": " + err.Error())
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// Rename returns sources of files which are changed by renaming
+// identifier at position of file to id. References to identifier
+// are renamed in packages of project, as Refs finds them.
+//
+// Renaming is refused with error if definition is out of project,
+// if renamed code has errors such as identifier collisions, or if any
+// reference would refer to another definition, such as shadowing,
+// or if renamed local would shadow a definition of package scope.
+// Returns logs if packages or renamed code have errors,
+// sources are returned with logs of renamed code.
+func Rename(path string, row, column int, id string) ([]xlog.FixedSource, []xlog.CompilerLog, error) {
+	if !isId(id) {
+		return nil, nil, errors.New("invalid identifier: " + id)
+	}
+	pr, sym, logs, err := find(path, row, column)
+	if pr == nil {
+		return nil, logs, err
+	}
+	switch {
+	case sym.Kind == parser.SymbolNs, sym.Kind == parser.SymbolCppLink:
+		return nil, nil, fmt.Errorf("%s %s can not be renamed", sym.Kind, sym.Id)
+	case sym.Tok.File == nil:
+		return nil, nil, fmt.Errorf("built-in %s %s can not be renamed", sym.Kind, sym.Id)
+	case sym.Id == id:
+		return nil, nil, nil
+	}
+	// Shadowing is not an error for compiler, and references before
+	// declaration of local are still refer to shadowed definition.
+	if sym.Kind == parser.SymbolVar {
+		if def, ok := pr.scope.ScopeSymbol(id); ok {
+			return nil, nil, fmt.Errorf("renaming %s %s to %s would shadow %s %s",
+				sym.Kind, sym.Id, id, def.Kind, def.Id)
+		}
+	}
+	rn := &renaming{columns: map[string]map[int][]int{}, delta: len(id) - len(sym.Id)}
+	group := pr.group(sym)
+	locs := pr.locations(group)
+	for _, loc := range locs {
+		if !xio.IsInProject(loc.Path) {
+			return nil, nil, fmt.Errorf("%s: %s %s is out of project", loc, sym.Kind, sym.Id)
+		}
+		if pr.refs[loc].id != sym.Id {
+			return nil, nil, fmt.Errorf("%s: reference of %s %s can not be renamed", loc, sym.Kind, sym.Id)
+		}
+		rows := rn.columns[loc.Path]
+		if rows == nil {
+			rows = map[int][]int{}
+			rn.columns[loc.Path] = rows
+		}
+		rows[loc.Row] = append(rows[loc.Row], loc.Column)
+	}
+	sources, err := rn.sources(sym.Id, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, src := range sources {
+		xio.Overlay[src.Path] = src.New
+	}
+	defer func() {
+		for _, src := range sources {
+			delete(xio.Overlay, src.Path)
+		}
+	}()
+	renamed := &project{pkgs: pr.pkgs}
+	if logs := renamed.compile(); logs != nil {
+		// Logs are refer to renamed sources.
+		return sources, logs, fmt.Errorf("renaming %s %s to %s causes errors", sym.Kind, sym.Id, id)
+	}
+	all := make([]Location, 0, len(pr.refs))
+	for loc := range pr.refs {
+		all = append(all, loc)
+	}
+	sortLocations(all)
+	for _, loc := range all {
+		r := pr.refs[loc]
+		loc = rn.shift(loc)
+		if rr, ok := renamed.refs[loc]; !ok || rr.def != rn.shiftKey(r.def) {
+			if group[r.def] {
+				r.id = id
+			}
+			return nil, nil, fmt.Errorf("%s: %s would refer to another definition after renaming %s %s to %s",
+				loc, r.id, sym.Kind, sym.Id, id)
+		}
+	}
+	return sources, nil, nil
+}
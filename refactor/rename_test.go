@@ -0,0 +1,155 @@
+package refactor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/the-xlang/xxc/internal/testutil"
+	"github.com/the-xlang/xxc/pkg/x"
+	"github.com/the-xlang/xxc/pkg/xio"
+)
+
+func TestMain(m *testing.M) { testutil.Main(m) }
+
+const testMain = `use lib
+
+count: int = 1
+
+main() {
+	a: = 1
+	b: = lib::twice(a)
+	outln(count + a + b)
+}
+
+total() int {
+	n: = count
+	other: = 5
+	ret n + other
+}
+`
+
+const testLib = `pub twice(n int) int {
+	ret n * 2
+}
+`
+
+// writeProject writes project to temporary directory and returns directory.
+func writeProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{"main.xx": testMain, "lib/lib.xx": testLib}
+	for name, text := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestRename(t *testing.T) {
+	cases := []struct {
+		name   string
+		file   string
+		row    int
+		column int
+		id     string
+		// Error must contain err if not empty.
+		err  string
+		logs bool
+		// New contents of sources must contain these by file.
+		want map[string]string
+	}{
+		{
+			name: "function of package",
+			file: "lib/lib.xx", row: 1, column: 5, id: "double",
+			want: map[string]string{
+				"lib/lib.xx": "pub double(n int) int",
+				"main.xx":    "b: = lib::double(a)",
+			},
+		},
+		{
+			name: "local variable",
+			file: "main.xx", row: 6, column: 5, id: "first",
+			want: map[string]string{"main.xx": "outln(count + first + b)"},
+		},
+		{
+			name: "collision",
+			file: "main.xx", row: 6, column: 5, id: "b",
+			err:  "causes errors",
+			logs: true,
+		},
+		{
+			name: "shadowing",
+			file: "main.xx", row: 3, column: 1, id: "a",
+			err: "would refer to another definition",
+		},
+		{
+			name: "shadowing global used before",
+			file: "main.xx", row: 13, column: 5, id: "count",
+			err: "would shadow global count",
+		},
+		{
+			name: "shadowing function",
+			file: "main.xx", row: 13, column: 5, id: "main",
+			err: "would shadow func main",
+		},
+		{
+			name: "shadowing namespace",
+			file: "main.xx", row: 13, column: 5, id: "lib",
+			err: "would shadow namespace lib",
+		},
+		{
+			name: "shadowing built-in",
+			file: "main.xx", row: 13, column: 5, id: "outln",
+			err: "would shadow func outln",
+		},
+		{
+			name: "built-in",
+			file: "main.xx", row: 8, column: 5, id: "print",
+			err: "built-in",
+		},
+		{
+			name: "keyword",
+			file: "main.xx", row: 6, column: 5, id: "if",
+			err: "invalid identifier",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := writeProject(t)
+			defer func(path string) { x.ProjectPath = path }(x.ProjectPath)
+			x.ProjectPath = dir
+			sources, logs, err := Rename(filepath.Join(dir, c.file), c.row, c.column, c.id)
+			if len(xio.Overlay) > 0 {
+				t.Errorf("overlay is not cleaned: %v", xio.Overlay)
+			}
+			switch {
+			case c.err == "" && err != nil:
+				t.Fatalf("unexpected error: %v %v", err, logs)
+			case c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)):
+				t.Fatalf("want error %q, got %v", c.err, err)
+			case c.logs != (logs != nil):
+				t.Fatalf("want logs %v, got %v", c.logs, logs)
+			}
+			if c.logs && len(sources) == 0 {
+				t.Errorf("renamed sources are not returned with logs")
+			}
+			news := map[string]string{}
+			for _, src := range sources {
+				rel, _ := filepath.Rel(dir, src.Path)
+				news[filepath.ToSlash(rel)] = src.New
+			}
+			for file, text := range c.want {
+				if !strings.Contains(news[file], text) {
+					t.Errorf("%s: missing %q in:\n%s", file, text, news[file])
+				}
+			}
+		})
+	}
+}
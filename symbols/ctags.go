@@ -0,0 +1,71 @@
+package symbols
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/the-xlang/xxc/parser"
+	"github.com/the-xlang/xxc/pkg/x"
+)
+
+// Kinds of tags by kinds of symbols.
+var ctagsKinds = map[string]string{
+	parser.SymbolFunc:     "f",
+	parser.SymbolMethod:   "m",
+	parser.SymbolField:    "w",
+	parser.SymbolStruct:   "s",
+	parser.SymbolTrait:    "i",
+	parser.SymbolEnum:     "g",
+	parser.SymbolEnumItem: "e",
+	parser.SymbolType:     "t",
+	parser.SymbolGlobal:   "v",
+	parser.SymbolCppLink:  "p",
+}
+
+// ctagsLine returns tag line of declaration in extended format.
+// Address of tag is the line number of declaration.
+func ctagsLine(d Decl, base string) string {
+	fields := []string{
+		d.Id,
+		relPath(base, d.Tok.File.Path()),
+		strconv.Itoa(d.Tok.Row) + `;"`,
+		ctagsKinds[d.Kind],
+		"line:" + strconv.Itoa(d.Tok.Row),
+	}
+	if d.Scope != "" {
+		fields = append(fields, d.ScopeKind+":"+d.Scope)
+	}
+	if d.Pub {
+		fields = append(fields, "access:public")
+	} else {
+		fields = append(fields, "access:private")
+	}
+	if isFunc(d) {
+		fields = append(fields, "signature:"+d.Type)
+	} else if t := typeOf(d); t != "" {
+		fields = append(fields, "typeref:typename:"+t)
+	}
+	return strings.Join(fields, "\t")
+}
+
+// Ctags returns declarations as tags file of ctags.
+// Paths are relative to base if they are in base.
+// Tags are sorted by names, so editors can use binary search.
+func Ctags(decls []Decl, base string) string {
+	lines := make([]string, len(decls))
+	for i, d := range decls {
+		lines[i] = ctagsLine(d, base)
+	}
+	sort.Strings(lines)
+	var tags strings.Builder
+	tags.WriteString("!_TAG_FILE_FORMAT\t2\t/extended format/\n")
+	tags.WriteString("!_TAG_FILE_SORTED\t1\t/0=unsorted, 1=sorted/\n")
+	tags.WriteString("!_TAG_PROGRAM_NAME\txxc\t//\n")
+	tags.WriteString("!_TAG_PROGRAM_VERSION\t" + x.Version + "\t//\n")
+	for _, line := range lines {
+		tags.WriteString(line)
+		tags.WriteByte('\n')
+	}
+	return tags.String()
+}
@@ -0,0 +1,139 @@
+package symbols
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/the-xlang/xxc/parser"
+	"github.com/the-xlang/xxc/pkg/x"
+	"github.com/the-xlang/xxc/pkg/xio"
+	"github.com/the-xlang/xxc/pkg/xlog"
+)
+
+type Decl = parser.Decl
+
+type symbol struct {
+	Kind      string `json:"kind"`
+	Id        string `json:"id"`
+	ScopeKind string `json:"scope_kind,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	Pub       bool   `json:"pub"`
+	Signature string `json:"signature,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Desc      string `json:"description,omitempty"`
+	Path      string `json:"path"`
+	Row       int    `json:"row"`
+	Column    int    `json:"column"`
+}
+
+// isFunc reports declaration has signature of function.
+func isFunc(d Decl) bool {
+	switch d.Kind {
+	case parser.SymbolFunc, parser.SymbolMethod, parser.SymbolCppLink:
+		return true
+	}
+	return false
+}
+
+// typeOf returns data type of declaration.
+// Returns empty string for declarations which have not data type.
+func typeOf(d Decl) string {
+	switch d.Kind {
+	case parser.SymbolGlobal, parser.SymbolField, parser.SymbolEnum, parser.SymbolType:
+		return d.Type
+	}
+	return ""
+}
+
+// relPath returns path relative to base if path is in base.
+func relPath(base, path string) string {
+	rel, err := filepath.Rel(base, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
+
+// inStd reports path is in standard library.
+func inStd(path string) bool {
+	return strings.HasPrefix(path, x.StdlibPath+string(filepath.Separator))
+}
+
+// Project returns declarations of packages of project and packages
+// of standard library which are used by them. Tests of project are
+// included. Standard library and output directory are not packages of project.
+// Returns logs of first package which has errors.
+func Project() ([]Decl, []xlog.CompilerLog, error) {
+	out, _ := filepath.Abs(x.Set.CppOutDir)
+	files, err := xio.Packages(x.ProjectPath, x.StdlibPath, out)
+	if err != nil {
+		return nil, nil, err
+	}
+	projectPkgs := len(files)
+	used := map[string]bool{}
+	var decls []Decl
+	parser.Reset()
+	// Used packages of standard library are appended to files.
+	for i := 0; i < len(files); i++ {
+		f, err := xio.Openfx(files[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		p := parser.New(f)
+		p.Test = i < projectPkgs
+		p.Parsef(false, true)
+		if len(p.Errors) > 0 {
+			return nil, p.Errors, nil
+		}
+		decls = append(decls, p.Decls()...)
+		for _, u := range p.Uses {
+			if used[u.Path] || !inStd(u.Path) {
+				continue
+			}
+			used[u.Path] = true
+			// Paths of cpp links are header files.
+			if info, err := os.Stat(u.Path); err != nil || !info.IsDir() {
+				continue
+			}
+			file, err := xio.PackageFile(u.Path)
+			if err != nil {
+				return nil, nil, err
+			}
+			if file != "" {
+				files = append(files, file)
+			}
+		}
+	}
+	return decls, nil, nil
+}
+
+// JSON returns declarations as JSON.
+// Paths are relative to base if they are in base.
+func JSON(decls []Decl, base string) (string, error) {
+	symbols := make([]symbol, len(decls))
+	for i, d := range decls {
+		s := symbol{
+			Kind:      d.Kind,
+			Id:        d.Id,
+			ScopeKind: d.ScopeKind,
+			Scope:     d.Scope,
+			Pub:       d.Pub,
+			Type:      typeOf(d),
+			Desc:      strings.TrimSpace(d.Desc),
+			Path:      relPath(base, d.Tok.File.Path()),
+			Row:       d.Tok.Row,
+			Column:    d.Tok.Column,
+		}
+		if isFunc(d) {
+			s.Signature = d.Type
+		}
+		symbols[i] = s
+	}
+	bytes, err := json.MarshalIndent(symbols, "", "\t")
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
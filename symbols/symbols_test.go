@@ -0,0 +1,251 @@
+package symbols
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/the-xlang/xxc/internal/testutil"
+	"github.com/the-xlang/xxc/parser"
+	"github.com/the-xlang/xxc/pkg/x"
+)
+
+func TestMain(m *testing.M) { testutil.Main(m) }
+
+var testProject = map[string]string{
+	"main.xx": `use lib
+
+// doc: Count of calls.
+pub count: int = 0
+
+enum color: u8 {
+	red,
+	green,
+}
+
+type id u64
+
+pub trait shape {
+	area() f64
+}
+
+pub struct point {
+	pub x: int
+	y: int
+}
+
+impl point {
+	pub len() int { ret .x + .y }
+}
+
+main() {
+	outln(lib::twice(2))
+}
+`,
+	"main_test.xx": `@test
+test_twice() {
+	if lib::twice(1) != 2 {
+		panic("twice is wrong")
+	}
+}
+`,
+	"lib/lib.xx": `use std::math
+
+pub twice(n int) int {
+	ret n * 2
+}
+`,
+	// Output directory is not package of project.
+	"dist/gen.xx": `generated() {}
+`,
+}
+
+// writeProject writes project to temporary directory and sets
+// paths of project and output directory.
+func writeProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, text := range testProject {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	projectPath, cppOutDir := x.ProjectPath, x.Set.CppOutDir
+	t.Cleanup(func() { x.ProjectPath, x.Set.CppOutDir = projectPath, cppOutDir })
+	x.ProjectPath = dir
+	x.Set.CppOutDir = filepath.Join(dir, "dist")
+	return dir
+}
+
+func project(t *testing.T) ([]Decl, string) {
+	t.Helper()
+	dir := writeProject(t)
+	decls, logs, err := Project()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) > 0 {
+		t.Fatalf("project has errors: %+v", logs[0])
+	}
+	return decls, dir
+}
+
+// find returns declaration by kind, scope and identifier.
+func find(decls []Decl, kind, scope, id string) (Decl, bool) {
+	for _, d := range decls {
+		if d.Kind == kind && d.Scope == scope && d.Id == id {
+			return d, true
+		}
+	}
+	return Decl{}, false
+}
+
+func TestProject(t *testing.T) {
+	decls, dir := project(t)
+	cases := []struct {
+		kind, scope, id string
+		// Path of file relative to project, empty if not found.
+		path string
+		pub  bool
+		row  int
+	}{
+		{parser.SymbolGlobal, "", "count", "main.xx", true, 4},
+		{parser.SymbolEnum, "", "color", "main.xx", false, 6},
+		{parser.SymbolEnumItem, "color", "green", "main.xx", false, 8},
+		{parser.SymbolType, "", "id", "main.xx", false, 11},
+		{parser.SymbolTrait, "", "shape", "main.xx", true, 13},
+		{parser.SymbolMethod, "shape", "area", "main.xx", true, 14},
+		{parser.SymbolStruct, "", "point", "main.xx", true, 17},
+		{parser.SymbolField, "point", "x", "main.xx", true, 18},
+		{parser.SymbolField, "point", "y", "main.xx", false, 19},
+		{parser.SymbolMethod, "point", "len", "main.xx", true, 23},
+		{parser.SymbolFunc, "", "main", "main.xx", false, 26},
+		{parser.SymbolFunc, "", "test_twice", "main_test.xx", false, 2},
+		{parser.SymbolFunc, "", "twice", filepath.Join("lib", "lib.xx"), true, 3},
+		{parser.SymbolFunc, "", "generated", "", false, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.kind+" "+c.id, func(t *testing.T) {
+			d, ok := find(decls, c.kind, c.scope, c.id)
+			switch {
+			case c.path == "" && ok:
+				t.Fatalf("unexpected declaration in %s", d.Tok.File.Path())
+			case c.path == "":
+				return
+			case !ok:
+				t.Fatal("declaration is not found")
+			}
+			if path := relPath(dir, d.Tok.File.Path()); path != c.path {
+				t.Errorf("path is %s, want %s", path, c.path)
+			}
+			if d.Pub != c.pub {
+				t.Errorf("pub is %v, want %v", d.Pub, c.pub)
+			}
+			if d.Tok.Row != c.row {
+				t.Errorf("row is %d, want %d", d.Tok.Row, c.row)
+			}
+		})
+	}
+	// Used packages of standard library are included.
+	std := false
+	for _, d := range decls {
+		if inStd(d.Tok.File.Path()) {
+			std = true
+			break
+		}
+	}
+	if !std {
+		t.Error("declarations of used standard library are not included")
+	}
+}
+
+func TestRelPath(t *testing.T) {
+	base := filepath.Join(string(filepath.Separator), "project")
+	cases := []struct {
+		path, want string
+	}{
+		{filepath.Join(base, "main.xx"), "main.xx"},
+		{filepath.Join(base, "lib", "lib.xx"), filepath.Join("lib", "lib.xx")},
+		{filepath.Join(base, "..", "other", "main.xx"), filepath.Join(string(filepath.Separator), "other", "main.xx")},
+		// Directory which has name starts with two dots is in base.
+		{filepath.Join(base, "..lib", "lib.xx"), filepath.Join("..lib", "lib.xx")},
+	}
+	for _, c := range cases {
+		if got := relPath(base, c.path); got != c.want {
+			t.Errorf("relPath(%q): got %q, want %q", c.path, got, c.want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	decls, dir := project(t)
+	text, err := JSON(decls, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var symbols []symbol
+	if err := json.Unmarshal([]byte(text), &symbols); err != nil {
+		t.Fatal(err)
+	}
+	if len(symbols) != len(decls) {
+		t.Fatalf("got %d symbols, want %d", len(symbols), len(decls))
+	}
+	cases := []symbol{
+		{Kind: parser.SymbolGlobal, Id: "count", Pub: true, Type: "int", Desc: "Count of calls.", Path: "main.xx", Row: 4, Column: 5},
+		{Kind: parser.SymbolEnum, Id: "color", Type: "u8", Path: "main.xx", Row: 6, Column: 6},
+		{Kind: parser.SymbolField, Id: "x", ScopeKind: parser.SymbolStruct, Scope: "point", Pub: true, Type: "int", Path: "main.xx", Row: 18, Column: 9},
+		{Kind: parser.SymbolFunc, Id: "twice", Pub: true, Signature: "(int)int", Path: filepath.Join("lib", "lib.xx"), Row: 3, Column: 5},
+	}
+	for _, want := range cases {
+		found := false
+		for _, s := range symbols {
+			if s.Kind == want.Kind && s.Scope == want.Scope && s.Id == want.Id {
+				found = true
+				if s != want {
+					t.Errorf("got %+v, want %+v", s, want)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("%s %s is not found", want.Kind, want.Id)
+		}
+	}
+}
+
+func TestCtags(t *testing.T) {
+	decls, dir := project(t)
+	tags := Ctags(decls, dir)
+	lines := strings.Split(strings.TrimSuffix(tags, "\n"), "\n")
+	if len(lines) != len(decls)+4 {
+		t.Fatalf("got %d lines, want %d", len(lines), len(decls)+4)
+	}
+	for i := 1; i < len(lines); i++ {
+		if lines[i-1] > lines[i] {
+			t.Errorf("tags are not sorted: %q before %q", lines[i-1], lines[i])
+		}
+	}
+	cases := []string{
+		"count\tmain.xx\t4;\"\tv\tline:4\taccess:public\ttyperef:typename:int",
+		"green\tmain.xx\t8;\"\te\tline:8\tenum:color\taccess:private",
+		"len\tmain.xx\t23;\"\tm\tline:23\tstruct:point\taccess:public\tsignature:()int",
+		"twice\t" + filepath.Join("lib", "lib.xx") + "\t3;\"\tf\tline:3\taccess:public\tsignature:(int)int",
+	}
+	for _, want := range cases {
+		found := false
+		for _, line := range lines {
+			if line == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("tag is not found: %q", want)
+		}
+	}
+}